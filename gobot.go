@@ -1,33 +1,203 @@
 package main
 
+//go:generate sh -c "go run . -schema > config.schema.json"
+
 import (
 	"flag"
+	"fmt"
+	"os"
+	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/c0nvulsiv3/gobot/gobot"
 )
 
-var (
-	conf gobot.Configuration
-)
+const usage = `Usage: gobot [command] [flags]
+
+Commands:
+  run                  Start the bot (default)
+  validate-config      Check the configuration and report all problems
+  register-commands    Register the slash commands globally or for a guild
+  unregister-commands  Remove the slash commands globally or for a guild
+  list-commands        List the registered slash commands
+  export-state         Write the persisted bot data as JSON
+  import-state         Replace the persisted bot data with exported JSON
+
+Run gobot [command] -h for the flags of a command.
+`
 
-func init() {
+var commands = map[string]func(args []string){
+	"run":                 run,
+	"validate-config":     validateConfig,
+	"register-commands":   registerCommands,
+	"unregister-commands": unregisterCommands,
+	"list-commands":       listCommands,
+	"export-state":        exportState,
+	"import-state":        importState,
+}
+
+func main() {
+	// Running the bot is the default, so plain flags keep working
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		run(os.Args[1:])
+		return
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command(os.Args[2:])
+}
+
+func run(args []string) {
 	// Parse input for config file
-	var configFile string
-	flag.StringVar(&configFile, "config", "config.json", "Path to configuration file")
-	flag.Parse()
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	configFile := flags.String("config", gobot.DefaultConfigFile, "Path to configuration file")
+	printSchema := flags.Bool("schema", false, "Print the JSON schema of the configuration and exit")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage+"\nFlags of run:\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
 
-	if len(configFile) == 0 {
+	if *printSchema {
+		schema, err := gobot.ConfigSchema()
+		if err != nil {
+			gobot.Logger.Fatal("Could not generate configuration schema: ", err)
+		}
+		fmt.Println(string(schema))
+		return
+	}
+
+	if len(*configFile) == 0 {
 		gobot.Logger.Warn("Usage: gobot -config")
-		flag.PrintDefaults()
+		flags.PrintDefaults()
 	}
 
-	conf = gobot.ReadConfig(configFile)
+	conf := gobot.ReadConfig(*configFile)
 
 	// Set up logger with the specified configurations
 	gobot.Logger.Info("Setting up logger")
 	gobot.SetLoggerConfig(conf)
+
+	gobot.StartBot(*configFile, conf)
 }
 
-func main() {
-	gobot.StartBot(conf)
+// Loads and validates the configuration and reports all problems without starting the bot.
+func validateConfig(args []string) {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	configFile := flags.String("config", gobot.DefaultConfigFile, "Path to configuration file")
+	_ = flags.Parse(args)
+
+	if _, err := gobot.LoadConfig(*configFile); err != nil {
+		fail(err)
+	}
+	fmt.Println("Configuration is valid.")
+}
+
+func registerCommands(args []string) {
+	conf, guildID := commandFlags("register-commands", args)
+	registered, err := gobot.RegisterCommands(conf, guildID)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("Registered %d commands %s.\n", len(registered), scope(guildID))
+}
+
+func unregisterCommands(args []string) {
+	conf, guildID := commandFlags("unregister-commands", args)
+	if err := gobot.UnregisterCommands(conf, guildID); err != nil {
+		fail(err)
+	}
+	fmt.Printf("Removed all commands %s.\n", scope(guildID))
+}
+
+func listCommands(args []string) {
+	conf, guildID := commandFlags("list-commands", args)
+	registered, err := gobot.ListCommands(conf, guildID)
+	if err != nil {
+		fail(err)
+	}
+	for _, command := range registered {
+		if command.Type == discordgo.MessageApplicationCommand {
+			fmt.Printf("%s\t%s\t(message command)\n", command.ID, command.Name)
+		} else {
+			fmt.Printf("%s\t/%s\t%s\n", command.ID, command.Name, command.Description)
+		}
+	}
+}
+
+func exportState(args []string) {
+	flags := flag.NewFlagSet("export-state", flag.ExitOnError)
+	configFile := flags.String("config", gobot.DefaultConfigFile, "Path to configuration file")
+	file := flags.String("file", "-", "File to export to, - for stdout")
+	_ = flags.Parse(args)
+
+	conf := loadConfig(*configFile)
+	out := os.Stdout
+	if *file != "-" {
+		var err error
+		if out, err = os.Create(*file); err != nil {
+			fail(err)
+		}
+		defer out.Close()
+	}
+	if err := gobot.ExportState(conf, out); err != nil {
+		fail(err)
+	}
+}
+
+func importState(args []string) {
+	flags := flag.NewFlagSet("import-state", flag.ExitOnError)
+	configFile := flags.String("config", gobot.DefaultConfigFile, "Path to configuration file")
+	file := flags.String("file", "-", "File to import from, - for stdin")
+	_ = flags.Parse(args)
+
+	conf := loadConfig(*configFile)
+	in := os.Stdin
+	if *file != "-" {
+		var err error
+		if in, err = os.Open(*file); err != nil {
+			fail(err)
+		}
+		defer in.Close()
+	}
+	if err := gobot.ImportState(conf, in); err != nil {
+		fail(err)
+	}
+	fmt.Println("Imported state. Send SIGHUP to running bots or restart them to apply it.")
+}
+
+// Parses the flags shared by the command registration subcommands.
+func commandFlags(name string, args []string) (gobot.Configuration, string) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	configFile := flags.String("config", gobot.DefaultConfigFile, "Path to configuration file")
+	guildID := flags.String("guild", "", "Guild ID to manage commands for instead of global commands")
+	_ = flags.Parse(args)
+
+	return loadConfig(*configFile), *guildID
+}
+
+func loadConfig(configFile string) gobot.Configuration {
+	// Keep the output of operations free of setup logs
+	gobot.Logger.SetOutput(os.Stderr)
+	conf, err := gobot.LoadConfig(configFile)
+	if err != nil {
+		fail(err)
+	}
+	return conf
+}
+
+func scope(guildID string) string {
+	if guildID == "" {
+		return "globally"
+	}
+	return "for guild " + guildID
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
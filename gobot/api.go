@@ -0,0 +1,238 @@
+package gobot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Names of the repeating modes in the API
+var repeatingModeNames = map[RepeatingMode]string{
+	RepeatingModeOff:     "off",
+	RepeatingModeSong:    "single",
+	RepeatingModeQueue:   "all",
+	RepeatingModeSection: "section",
+}
+
+// Track as returned by the admin API.
+type TrackState struct {
+	Title      string `json:"title"`
+	Author     string `json:"author"`
+	URI        string `json:"uri,omitempty"`
+	Identifier string `json:"identifier"`
+	Source     string `json:"source"`
+	LengthMs   int64  `json:"length_ms"`
+	IsStream   bool   `json:"is_stream"`
+}
+
+// Player of a guild as returned by the admin API.
+type PlayerState struct {
+	GuildID    string       `json:"guild_id"`
+	Playing    bool         `json:"playing"`
+	Paused     bool         `json:"paused"`
+	PositionMs int64        `json:"position_ms"`
+	Mode       string       `json:"mode"`
+	Autoplay   bool         `json:"autoplay"`
+	Track      *TrackState  `json:"track"`
+	Queue      []TrackState `json:"queue"`
+}
+
+type apiRequest struct {
+	Query     string `json:"query"`
+	ChannelID string `json:"channel_id"`
+	Paused    bool   `json:"paused"`
+	Position  string `json:"position"`
+	Mode      string `json:"mode"`
+}
+
+func newTrackState(track lavalink.AudioTrack) TrackState {
+	state := TrackState{
+		Title:      track.Info().Title,
+		Author:     track.Info().Author,
+		Identifier: track.Info().Identifier,
+		Source:     track.Info().SourceName,
+		LengthMs:   track.Info().Length.Milliseconds(),
+		IsStream:   track.Info().IsStream,
+	}
+	if track.Info().URI != nil {
+		state.URI = *track.Info().URI
+	}
+	return state
+}
+
+func newPlayerState(manager *PlayerManager) PlayerState {
+	state := PlayerState{
+		GuildID:  manager.GuildID,
+		Playing:  manager.isPlaying(),
+		Paused:   manager.Player.Paused(),
+		Mode:     repeatingModeNames[manager.mode()],
+		Autoplay: manager.autoplayEnabled(),
+		Queue:    []TrackState{},
+	}
+	if track := manager.Player.PlayingTrack(); track != nil && state.Playing {
+		trackState := newTrackState(track)
+		state.Track = &trackState
+		state.PositionMs = manager.Player.Position().Milliseconds()
+	}
+	for _, track := range manager.getAllTracks() {
+		state.Queue = append(state.Queue, newTrackState(track))
+	}
+	return state
+}
+
+// Authenticated JSON API mirroring the slash commands:
+//
+//	GET    /api/guilds                 guilds with an active player
+//	GET    /api/guilds/{id}            playing track and queue
+//	POST   /api/guilds/{id}/queue      enqueue {"query", "channel_id"}
+//	DELETE /api/guilds/{id}/queue      purge the queue
+//	POST   /api/guilds/{id}/skip
+//	POST   /api/guilds/{id}/pause      {"paused"}
+//	POST   /api/guilds/{id}/seek       {"position"}
+//	POST   /api/guilds/{id}/mode       {"mode"}
+//	POST   /api/guilds/{id}/leave
+func (b *Bot) adminAPI(w http.ResponseWriter, r *http.Request) {
+	if b.config().AdminToken == "" {
+		writeAPIError(w, http.StatusNotFound, errors.New("admin API is disabled"))
+		return
+	}
+	if !b.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeAPIError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/guilds"), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		players := []PlayerState{}
+		for _, manager := range b.managers() {
+			players = append(players, newPlayerState(manager))
+		}
+		writeAPI(w, http.StatusOK, players)
+		return
+	}
+
+	guildID, action, _ := strings.Cut(path, "/")
+	apiLogger := Logger.WithField("api", r.Method+" "+r.URL.Path)
+
+	var request apiRequest
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAPIError(w, http.StatusBadRequest, errors.New("invalid JSON body"))
+			return
+		}
+	}
+
+	// Enqueueing creates the player, everything else needs one
+	if action == "queue" && r.Method == http.MethodPost {
+		if err := b.apiEnqueue(r, guildID, request); err != nil {
+			apiLogger.Warn("Could not enqueue: ", err)
+			writeAPIError(w, apiStatus(err), err)
+			return
+		}
+		b.writePlayer(w, guildID)
+		return
+	}
+
+	manager, ok := b.manager(guildID)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, errors.New("no active player in guild "+guildID))
+		return
+	}
+
+	var err error
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "queue" && r.Method == http.MethodDelete:
+		err = b.purgeQueue(guildID)
+	case action == "skip" && r.Method == http.MethodPost:
+		err = b.skip(b.Session, guildID)
+	case action == "pause" && r.Method == http.MethodPost:
+		err = b.pause(guildID, request.Paused)
+	case action == "seek" && r.Method == http.MethodPost:
+		var position lavalink.Duration
+		if position, err = parseTimestamp(request.Position); err == nil {
+			_, err = b.seekPlaying(guildID, position, false)
+		}
+	case action == "mode" && r.Method == http.MethodPost:
+		err = b.setMode(guildID, request.Mode)
+	case action == "leave" && r.Method == http.MethodPost:
+		if err = b.leave(b.Session, guildID); err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, errors.New("unknown endpoint"))
+		return
+	}
+	if err != nil {
+		apiLogger.Warn("Request failed: ", err)
+		writeAPIError(w, apiStatus(err), err)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, newPlayerState(manager))
+}
+
+// Writes the player of the guild, which may have left since the request changed it.
+func (b *Bot) writePlayer(w http.ResponseWriter, guildID string) {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, errors.New("no active player in guild "+guildID))
+		return
+	}
+	writeAPI(w, http.StatusOK, newPlayerState(manager))
+}
+
+// Joins the requested voice channel if the bot is not connected yet and queues the loaded tracks.
+func (b *Bot) apiEnqueue(r *http.Request, guildID string, request apiRequest) error {
+	if strings.TrimSpace(request.Query) == "" {
+		return errors.New("query is required")
+	}
+
+	if state, _ := b.Session.VoiceState(guildID, b.Session.UserID()); state == nil {
+		if request.ChannelID == "" {
+			return errors.New("bot is not in a voice channel. Set channel_id to join one")
+		}
+		if err := b.Session.ChannelVoiceJoinManual(guildID, request.ChannelID, false, false); err != nil {
+			return fmt.Errorf("%w: %v", ErrVoiceJoin, err)
+		}
+	}
+
+	tracks, err := b.loadTracks(r.Context(), guildID, "api", request.Query)
+	if err != nil {
+		return err
+	}
+	return b.play(b.Session, guildID, tracks...)
+}
+
+// Whether the request has the admin token in the Authorization header. The admin token is never read
+// from the URL, since URLs end up in logs and browser histories.
+func (b *Bot) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(b.config().AdminToken)) == 1
+}
+
+func writeAPI(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		Logger.Warn("Failed to write API response: ", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPI(w, status, map[string]string{"error": err.Error()})
+}
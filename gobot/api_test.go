@@ -0,0 +1,151 @@
+package gobot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Sends a request with the body to the handler and decodes the JSON response into result if it is set.
+func serveJSON(t *testing.T, handler http.HandlerFunc, method string, target string, header string, body string, result interface{}) int {
+	t.Helper()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if header != "" {
+		request.Header.Set("Authorization", header)
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	if result != nil && recorder.Code < 300 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+			t.Fatalf("decoding response %q: %v", recorder.Body, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestAdminAPIAuthorization(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+
+	if status := serveJSON(t, bot.adminAPI, http.MethodGet, "/api/guilds", "Bearer ", "", nil); status != http.StatusNotFound {
+		t.Errorf("expected the API to be disabled without admin token, got %d", status)
+	}
+
+	bot.Config.AdminToken = "secret"
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+	}{
+		{name: "missing token", target: "/api/guilds", status: http.StatusUnauthorized},
+		{name: "wrong token", target: "/api/guilds", header: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "token without scheme", target: "/api/guilds", header: "secret", status: http.StatusUnauthorized},
+		{name: "token in URL", target: "/api/guilds?token=secret", status: http.StatusUnauthorized},
+		{name: "admin token", target: "/api/guilds", header: "Bearer secret", status: http.StatusOK},
+		{name: "unknown guild", target: "/api/guilds/1", header: "Bearer secret", status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := serveJSON(t, bot.adminAPI, http.MethodGet, test.target, test.header, "", nil); status != test.status {
+				t.Errorf("expected status %d, got %d", test.status, status)
+			}
+		})
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	bot, session, link := newTestBot(t)
+	bot.Config.AdminToken = "secret"
+	link.tracks["https://example.com/a"] = []lavalink.AudioTrack{testTrack("a", "A", lavalink.Minute)}
+	link.tracks["https://example.com/b"] = []lavalink.AudioTrack{testTrack("b", "B", lavalink.Minute)}
+
+	request := func(method string, target string, body string, result interface{}) int {
+		t.Helper()
+		return serveJSON(t, bot.adminAPI, method, target, "Bearer secret", body, result)
+	}
+	guild := "/api/guilds/" + testGuildID
+
+	var player PlayerState
+	if status := request(http.MethodPost, guild+"/queue", `{"query": "https://example.com/a"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("expected enqueueing without voice channel to fail, got %d", status)
+	}
+	if status := request(http.MethodPost, guild+"/queue", `{"query": "https://example.com/a", "channel_id": "`+testChannelID+`"}`, &player); status != http.StatusOK || player.Track == nil || player.Track.Identifier != "a" {
+		t.Errorf("expected the track to play, got %d: %+v", status, player)
+	}
+	if state, _ := session.VoiceState(testGuildID, testBotID); state == nil || state.ChannelID != testChannelID {
+		t.Errorf("expected the bot to join the voice channel, got %+v", state)
+	}
+	if status := request(http.MethodPost, guild+"/queue", `{"query": "https://example.com/b"}`, &player); status != http.StatusOK || len(player.Queue) != 1 {
+		t.Errorf("expected a queued track, got %d: %+v", status, player)
+	}
+	if status := request(http.MethodPost, guild+"/queue", `{"query": "https://example.com/missing"}`, nil); status != http.StatusNotFound {
+		t.Errorf("expected a missing track to be not found, got %d", status)
+	}
+
+	var players []PlayerState
+	if status := request(http.MethodGet, "/api/guilds", "", &players); status != http.StatusOK || len(players) != 1 || players[0].GuildID != testGuildID {
+		t.Errorf("expected the player of the test guild, got %d: %+v", status, players)
+	}
+	if status := request(http.MethodGet, guild, "", &player); status != http.StatusOK || player.Track == nil || player.Track.Identifier != "a" {
+		t.Errorf("expected the playing track, got %d: %+v", status, player)
+	}
+
+	if status := request(http.MethodPost, guild+"/mode", `{"mode": "all"}`, &player); status != http.StatusOK || player.Mode != "all" {
+		t.Errorf("expected the mode to change, got %d: %+v", status, player)
+	}
+	if status := request(http.MethodPost, guild+"/mode", `{"mode": "sometimes"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("expected an unsupported mode to be rejected, got %d", status)
+	}
+	if status := request(http.MethodPost, guild+"/pause", `{"paused": true}`, &player); status != http.StatusOK || !player.Paused {
+		t.Errorf("expected the player to pause, got %d: %+v", status, player)
+	}
+	if status := request(http.MethodPost, guild+"/seek", `{"position": "0:30"}`, &player); status != http.StatusOK || player.PositionMs != 30000 {
+		t.Errorf("expected the player to seek, got %d: %+v", status, player)
+	}
+	// Players at the end of the track count as not playing, so the position is read from the player
+	if status := request(http.MethodPost, guild+"/seek", `{"position": "1h"}`, nil); status != http.StatusOK || bot.PlayerManagers[testGuildID].Player.Position() != lavalink.Minute {
+		t.Errorf("expected a seek past the end to stop at the end, got %d at %s", status, formatTimestamp(bot.PlayerManagers[testGuildID].Player.Position()))
+	}
+	if status := request(http.MethodPost, guild+"/mode", `{invalid`, nil); status != http.StatusBadRequest {
+		t.Errorf("expected invalid JSON to be rejected, got %d", status)
+	}
+	if status := request(http.MethodPut, guild+"/mode", "", nil); status != http.StatusNotFound {
+		t.Errorf("expected an unknown endpoint, got %d", status)
+	}
+
+	if status := request(http.MethodDelete, guild+"/queue", "", &player); status != http.StatusOK || len(player.Queue) != 0 {
+		t.Errorf("expected the queue to be purged, got %d: %+v", status, player)
+	}
+	if status := request(http.MethodPost, guild+"/leave", "", nil); status != http.StatusNoContent {
+		t.Errorf("expected the bot to leave, got %d", status)
+	}
+	if status := request(http.MethodGet, guild, "", nil); status != http.StatusNotFound {
+		t.Errorf("expected no player after leaving, got %d", status)
+	}
+}
+
+func TestAdminAPINotPlaying(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	bot.Config.AdminToken = "secret"
+	player := playTracks(t, bot, testTrack("a", "A", lavalink.Minute))
+	bot.PlayerManagers[testGuildID].setMode(RepeatingModeQueue)
+	if err := player.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	guild := "/api/guilds/" + testGuildID
+	for _, test := range []struct{ action, body string }{{"skip", ""}, {"seek", `{"position": "0:30"}`}} {
+		if status := serveJSON(t, bot.adminAPI, http.MethodPost, guild+"/"+test.action, "Bearer secret", test.body, nil); status != http.StatusConflict {
+			t.Errorf("expected %s without playing track to conflict, got %d", test.action, status)
+		}
+	}
+	if player.Position() != 0 {
+		t.Errorf("expected the stopped player not to seek, got %s", formatTimestamp(player.Position()))
+	}
+}
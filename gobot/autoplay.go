@@ -0,0 +1,92 @@
+package gobot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Number of recently played tracks autoplay avoids picking again
+const historySize = 50
+
+func (m *PlayerManager) autoplayEnabled() bool {
+	return m.Settings.Get(m.GuildID).Autoplay
+}
+
+// Remembers a played track so autoplay does not pick it again.
+func (m *PlayerManager) addHistory(track lavalink.AudioTrack) {
+	m.HistoryMu.Lock()
+	defer m.HistoryMu.Unlock()
+	m.History = append(m.History, track.Info().Identifier)
+	if len(m.History) > historySize {
+		m.History = m.History[len(m.History)-historySize:]
+	}
+}
+
+func (m *PlayerManager) inHistory(track lavalink.AudioTrack) bool {
+	m.HistoryMu.Lock()
+	defer m.HistoryMu.Unlock()
+	for _, identifier := range m.History {
+		if identifier == track.Info().Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// Plays a track related to the last track once the queue ran out.
+// Lavalink is queried in the background to not block the player event loop.
+func (m *PlayerManager) autoplay(last lavalink.AudioTrack) {
+	go func() {
+		track, err := m.relatedTrack(last)
+		if err != nil {
+			Logger.Warn("Autoplay could not find a related track: ", err)
+			return
+		}
+
+		// Tracks may have been queued by users in the meantime
+		if m.PeekQueue() != nil || m.isPlaying() {
+			Logger.Debug("Skipping autoplay since a track was queued.")
+			return
+		}
+
+		Logger.Debug("Autoplay picked track: ", track.Info().Title)
+		if err := m.Player.Play(track); err != nil {
+			Logger.Warn("Error playing autoplay track: ", err)
+		}
+	}()
+}
+
+// Looks up a related track via the YouTube mix playlist of the last track and falls back to a search on its author and title.
+func (m *PlayerManager) relatedTrack(last lavalink.AudioTrack) (lavalink.AudioTrack, error) {
+	queries := []string{lavalink.SearchTypeYoutube.Apply(last.Info().Author + " " + last.Info().Title)}
+	if last.Info().SourceName == "youtube" {
+		mix := "https://www.youtube.com/watch?v=" + last.Info().Identifier + "&list=RD" + last.Info().Identifier
+		queries = append([]string{mix}, queries...)
+	}
+
+	for _, query := range queries {
+		loadStart := time.Now()
+		result, err := m.Lavalink.LoadItem(context.TODO(), query)
+		observeLoad("autoplay", loadStart)
+		if err != nil {
+			Logger.Warn("Autoplay query failed: ", err)
+			continue
+		}
+
+		for _, restTrack := range result.Tracks {
+			track, err := m.Lavalink.DecodeTrack(restTrack.Track)
+			if err != nil {
+				continue
+			}
+			if track.Info().Identifier == last.Info().Identifier || track.Info().IsStream || m.inHistory(track) {
+				continue
+			}
+			return track, nil
+		}
+	}
+
+	return nil, errors.New("no unplayed related track found")
+}
@@ -3,20 +3,24 @@ package gobot
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"regexp"
+	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/disgolink/dgolink"
 	"github.com/disgoorg/disgolink/lavalink"
-	"github.com/disgoorg/snowflake/v2"
 )
 
 var (
-	urlPattern     = regexp.MustCompile("^https?://[-a-zA-Z0-9+&@#/%?=~_|!:,.;]*[-a-zA-Z0-9+&@#/%=~_|]?")
-	NumberEmojiMap = map[int]string{
+	urlPattern       = regexp.MustCompile("^https?://[-a-zA-Z0-9+&@#/%?=~_|!:,.;]*[-a-zA-Z0-9+&@#/%=~_|]?")
+	urlSearchPattern = regexp.MustCompile("https?://[-a-zA-Z0-9+&@#/%?=~_|!:,.;]*[-a-zA-Z0-9+&@#/%=~_|]")
+	NumberEmojiMap   = map[int]string{
 		1:  "1️⃣",
 		2:  "2️⃣",
 		3:  "3️⃣",
@@ -31,12 +35,23 @@ var (
 )
 
 type Bot struct {
-	Link           *dgolink.Link                             // Corresponding Link
-	PlayerManagers map[string]*PlayerManager                 // available playermanager, maps guildid to manager
-	TrackMap       map[string]map[string]lavalink.AudioTrack // maps query author and selected track id to track object
+	Config           Configuration                             // Currently applied configuration, read it with config() since reloads replace it
+	Session          Session                                   // discord session of the bot
+	Link             *dgolink.Link                             // Corresponding Link, manages the lavalink nodes
+	Nodes            *NodeMonitor                              // connection state and stats of the lavalink nodes
+	Lavalink         LavalinkClient                            // loads tracks and creates players via Link
+	PlayerManagers   map[string]*PlayerManager                 // available playermanager, maps guildid to manager
+	PlayerManagersMu sync.RWMutex                              // guards PlayerManagers, which HTTP handlers and timers read too
+	TrackMap         map[string]map[string]lavalink.AudioTrack // maps query author and selected track id to track object
+	Library          *Library                                  // local music library, nil if not configured. Read it with library()
+	ConfigMu         sync.RWMutex                              // guards Config and Library
+	Settings         *GuildSettingsStore                       // persisted per guild settings
+	Dashboard        *Dashboard                                // web dashboard clients and link signing
+	Events           *EventStream                              // subscribers of the player event stream
+	Bus              *EventBus                                 // internal events of players and commands
 }
 
-func StartBot(conf Configuration) {
+func StartBot(configFile string, conf Configuration) {
 	Logger.Info("Setting up discord bot session and lavalink node.")
 
 	dg, err := discordgo.New("Bot " + conf.DiscordToken)
@@ -44,26 +59,15 @@ func StartBot(conf Configuration) {
 		Logger.Fatal("Error creating discord session: ", err)
 	}
 
-	bot := &Bot{
-		Link:           dgolink.New(dg, lavalink.WithLogger(Logger)),
-		PlayerManagers: map[string]*PlayerManager{},
-		TrackMap:       map[string]map[string]lavalink.AudioTrack{},
+	bot, err := newBot(conf, NewSession(dg), dgolink.New(dg, lavalink.WithLogger(Logger)))
+	if err != nil {
+		Logger.Fatal("Error creating bot: ", err)
 	}
+	setThemes(conf.ThemeDir)
 
 	Logger.Debug("Adding event handlers.")
-	dg.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		// Redirect to correct event handler
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			if h, ok := CommandsHandlers[i.ApplicationCommandData().Name]; ok {
-				h(s, i, bot)
-			}
-		case discordgo.InteractionMessageComponent:
-
-			if h, ok := ComponentsHandlers[i.MessageComponentData().CustomID]; ok {
-				h(s, i, bot)
-			}
-		}
+	dg.AddHandler(func(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+		bot.handleInteraction(i)
 	})
 
 	Logger.Debug("Creating and adding slash commands.")
@@ -81,6 +85,15 @@ func StartBot(conf Configuration) {
 	bot.Link.BestNode().ConfigureResuming(conf.ResumeKey, conf.ResumeTimeOut)
 	// TODO rejoin if resuming session ...
 
+	bot.setLibrary(conf.LibraryDir)
+
+	go bot.watchConfig(configFile)
+
+	registerMetrics(dg, bot)
+	if conf.HTTPAddress != "none" {
+		go bot.serveHTTP(conf.HTTPAddress)
+	}
+
 	Logger.Info("Bot is running.")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
@@ -88,7 +101,75 @@ func StartBot(conf Configuration) {
 	Logger.Info("Shutting down bot due to syscalls or interupts: ", sc)
 }
 
-func (b *Bot) Play(s *discordgo.Session, i *discordgo.InteractionCreate, tracks ...lavalink.AudioTrack) error {
+// Creates the bot with its event subscribers. Nodes are registered separately once the session is open.
+func newBot(conf Configuration, session Session, link *dgolink.Link) (*Bot, error) {
+	settings, err := NewGuildSettingsStore(conf.GuildSettingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading guild settings: %w", err)
+	}
+
+	dashboard, err := NewDashboard()
+	if err != nil {
+		return nil, fmt.Errorf("error creating dashboard secret: %w", err)
+	}
+
+	bot := &Bot{
+		Config:         conf,
+		Session:        session,
+		Link:           link,
+		Nodes:          NewNodeMonitor(),
+		Lavalink:       NewLavalinkClient(link),
+		PlayerManagers: map[string]*PlayerManager{},
+		TrackMap:       map[string]map[string]lavalink.AudioTrack{},
+		Settings:       settings,
+		Dashboard:      dashboard,
+		Events:         NewEventStream(),
+		Bus:            NewEventBus(),
+	}
+	link.AddPlugins(bot.Nodes)
+	bot.subscribe()
+	return bot, nil
+}
+
+// Redirects interactions to the corresponding handler.
+func (b *Bot) handleInteraction(i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		name := i.ApplicationCommandData().Name
+		h, ok := CommandsHandlers[name]
+		if !ok {
+			b.publishCommand(i, commandResultUnknown)
+			return
+		}
+		if DJCommands[name] && !b.isDJ(i) {
+			djOnlyResponse(b.Session, i, b)
+			b.publishCommand(i, commandResultDenied)
+			return
+		}
+		b.publishCommand(i, commandResult(h(b.Session, i, b)))
+	case discordgo.InteractionMessageComponent:
+
+		if h, ok := ComponentsHandlers[i.MessageComponentData().CustomID]; ok {
+			h(b.Session, i, b)
+		}
+	}
+}
+
+func (b *Bot) publishCommand(i *discordgo.InteractionCreate, result string) {
+	event := CommandEvent{
+		Command: i.ApplicationCommandData().Name,
+		GuildID: i.GuildID,
+		Result:  result,
+		Time:    time.Now().UTC(),
+	}
+	if i.Member != nil {
+		event.UserID = i.Member.User.ID
+	}
+	recordMetrics(event)
+	b.Bus.Publish(event)
+}
+
+func (b *Bot) Play(s Session, i *discordgo.InteractionCreate, tracks ...lavalink.AudioTrack) error {
 	// find voicestate of query user (and connect)
 	voiceChannel, err := b.findChannelQueryUser(s, i, i.Member.User.ID)
 	if err != nil {
@@ -97,35 +178,91 @@ func (b *Bot) Play(s *discordgo.Session, i *discordgo.InteractionCreate, tracks
 		Logger.Debug("User found in: ", voiceChannel)
 	}
 
-	if state, _ := s.State.VoiceState(i.GuildID, s.State.User.ID); state == nil && voiceChannel != nil {
+	if state, _ := s.VoiceState(i.GuildID, s.UserID()); state == nil && voiceChannel != nil {
 		if err := s.ChannelVoiceJoinManual(i.GuildID, voiceChannel.ChannelID, false, false); err != nil {
 			Logger.Warn("Could not join user voice channel: ", err)
-			return errors.New("could not join voice state of user")
+			return fmt.Errorf("%w: %v", ErrVoiceJoin, err)
 		}
 	} else if voiceChannel == nil && state == nil {
-		return errors.New("both user and bot are not in a voice channel")
+		return ErrUserNotInVoice
 	}
 
-	return b.play(s, i.GuildID, tracks...)
+	if err := b.play(s, i.GuildID, tracks...); err != nil {
+		return err
+	}
+	b.publishQueued(i, tracks)
+	return nil
 }
 
-func (b *Bot) play(s *discordgo.Session, guildID string, tracks ...lavalink.AudioTrack) error {
+func (b *Bot) publishQueued(i *discordgo.InteractionCreate, tracks []lavalink.AudioTrack) {
+	event := QueuedEvent{
+		GuildID: i.GuildID,
+		UserID:  i.Member.User.ID,
+		User:    i.Member.User.Username,
+		Time:    time.Now().UTC(),
+	}
+	if i.Member.Nick != "" {
+		event.User = i.Member.Nick
+	}
+	for _, track := range tracks {
+		event.Titles = append(event.Titles, track.Info().Title)
+	}
+	b.Bus.Publish(event)
+}
+
+func (b *Bot) play(s Session, guildID string, tracks ...lavalink.AudioTrack) error {
 	// Create new manager for guildID if not available
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	Logger.Debug("Manager status: ", manager)
 	if !ok {
-		schneeFlogge, err := snowflake.Parse(guildID)
+		player, err := b.Lavalink.Player(guildID)
 		if err != nil {
-			Logger.Warn("Could not convert guildID to int64")
+			Logger.Warn("Could not create player: ", err)
+			return lavalinkError("player", err)
 		}
 
 		manager = &PlayerManager{
-			Player:        b.Link.Player(schneeFlogge),
+			Player:        player,
+			Lavalink:      b.Lavalink,
 			RepeatingMode: RepeatingModeOff,
-			PlayerSession: s,
+			GuildID:       guildID,
+			Settings:      b.Settings,
+		}
+		manager.OnIdle = func() {
+			Logger.Info("Leaving idle guild ", guildID)
+			if err := b.leave(s, guildID); err != nil {
+				Logger.Warn("Error leaving idle guild: ", err)
+			}
+		}
+		manager.Bus = b.Bus
+
+		// Another request may have created a manager for the guild in the meantime
+		b.PlayerManagersMu.Lock()
+		if existing, ok := b.PlayerManagers[guildID]; ok {
+			b.PlayerManagersMu.Unlock()
+			return b.queue(existing, tracks)
 		}
 		b.PlayerManagers[guildID] = manager
+		b.PlayerManagersMu.Unlock()
+		manager.emit(PlayerEvent{Type: EventPlayerCreated})
 		manager.Player.AddListener(manager)
+
+		// The player needs a node before the volume can be set
+		if volume := b.Settings.Get(guildID).Volume; volume != manager.Player.Volume() && manager.Player.Node() != nil {
+			if err := manager.Player.SetVolume(volume); err != nil {
+				Logger.Warn("Error setting default volume: ", err)
+			}
+		}
+	}
+
+	return b.queue(manager, tracks)
+}
+
+// Queues the tracks and starts playing if the player is idle.
+func (b *Bot) queue(manager *PlayerManager, tracks []lavalink.AudioTrack) error {
+	tracks, err := b.applyLimits(manager, tracks)
+	if err != nil {
+		return err
 	}
 
 	Logger.Debug("Player status: ", manager.Player)
@@ -144,71 +281,162 @@ func (b *Bot) play(s *discordgo.Session, guildID string, tracks ...lavalink.Audi
 	if track := manager.PopQueue(); track != nil {
 		Logger.Debug("Next track: ", track)
 		if err := manager.Player.Play(track); err != nil {
-			return err
+			return lavalinkError("play", err)
 		}
 	}
 
 	return nil
 }
 
-func (b *Bot) leave(s *discordgo.Session, guildID string) error {
+// Drops tracks exceeding the maximum track length and rejects tracks exceeding the maximum queue length of the guild.
+func (b *Bot) applyLimits(manager *PlayerManager, tracks []lavalink.AudioTrack) ([]lavalink.AudioTrack, error) {
+	settings := b.Settings.Get(manager.GuildID)
+
+	if settings.MaxTrackLength > 0 {
+		maxLength := lavalink.Duration(settings.MaxTrackLength) * lavalink.Minute
+		var allowed []lavalink.AudioTrack
+		for _, track := range tracks {
+			if track.Info().IsStream || track.Info().Length <= maxLength {
+				allowed = append(allowed, track)
+			}
+		}
+		if len(allowed) == 0 {
+			return nil, &LimitError{Setting: "max_track_length", Limit: settings.MaxTrackLength}
+		}
+		tracks = allowed
+	}
+
+	if settings.MaxQueueLength > 0 && len(manager.getAllTracks())+len(tracks) > settings.MaxQueueLength {
+		return nil, &LimitError{Setting: "max_queue_length", Limit: settings.MaxQueueLength}
+	}
+
+	return tracks, nil
+}
+
+// Checks whether the member may control playback. Everyone may if the guild has no DJ role.
+func (b *Bot) isDJ(i *discordgo.InteractionCreate) bool {
+	role := b.Settings.Get(i.GuildID).DJRole
+	if role == "" {
+		return true
+	}
+	if i.Member == nil {
+		return false
+	}
+	if canManageServer(i.Member) {
+		return true
+	}
+	for _, memberRole := range i.Member.Roles {
+		if memberRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+func canManageServer(member *discordgo.Member) bool {
+	return member.Permissions&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0
+}
+
+func (b *Bot) leave(s Session, guildID string) error {
 	// Leave channel
 	if err := s.ChannelVoiceJoinManual(guildID, "", false, false); err != nil {
 		return err
 	}
 
-	// Get rid of player and manager of player for this server
+	// Get rid of player and manager of player for this server. Removing it under the lock makes sure
+	// only one of concurrent leaves, e.g. by the idle timer and a command, destroys the player.
+	b.PlayerManagersMu.Lock()
 	manager, ok := b.PlayerManagers[guildID]
+	delete(b.PlayerManagers, guildID)
+	b.PlayerManagersMu.Unlock()
 	if !ok {
 		Logger.Warn("No player manager for guild available.")
-		return errors.New("no player manager available. Connect the bot first")
+		return ErrNoPlayer
 	}
 
+	manager.stopSectionLoop()
+	manager.stopIdleTimer()
+
 	// Appears to set the playing track to nil
 	if err := manager.Player.Stop(); err != nil {
-		return err
+		return lavalinkError("stop", err)
 	}
 	if err := manager.Player.Destroy(); err != nil {
-		return err
-	}
-	delete(b.PlayerManagers, guildID)
-
-	if err := s.UpdateGameStatus(0, ""); err != nil {
-		Logger.Warn("Error updating status: ", err)
+		return lavalinkError("destroy", err)
 	}
+	manager.emit(PlayerEvent{Type: EventPlayerDestroyed})
 	return nil
 }
 
-func (b *Bot) skip(s *discordgo.Session, guildID string) error {
+// Snapshot of the applied configuration.
+func (b *Bot) config() Configuration {
+	b.ConfigMu.RLock()
+	defer b.ConfigMu.RUnlock()
+	return b.Config
+}
+
+// Music library of the applied configuration, nil if it is disabled. Keep the result instead of calling it again,
+// since a reload may replace or remove the library in between.
+func (b *Bot) library() *Library {
+	b.ConfigMu.RLock()
+	defer b.ConfigMu.RUnlock()
+	return b.Library
+}
+
+// Player manager of the guild. Use it instead of indexing PlayerManagers, which is also written by other goroutines.
+func (b *Bot) manager(guildID string) (*PlayerManager, bool) {
+	b.PlayerManagersMu.RLock()
+	defer b.PlayerManagersMu.RUnlock()
 	manager, ok := b.PlayerManagers[guildID]
+	return manager, ok
+}
+
+// Snapshot of all player managers.
+func (b *Bot) managers() []*PlayerManager {
+	b.PlayerManagersMu.RLock()
+	defer b.PlayerManagersMu.RUnlock()
+	managers := make([]*PlayerManager, 0, len(b.PlayerManagers))
+	for _, manager := range b.PlayerManagers {
+		managers = append(managers, manager)
+	}
+	return managers
+}
+
+func (b *Bot) skip(s Session, guildID string) error {
+	manager, ok := b.manager(guildID)
 	if !ok {
 		Logger.Warn("No player manager for guild available.")
-		return errors.New("no player manager available. Connect the bot first")
+		return ErrNoPlayer
+	}
+	playingTrack := manager.Player.PlayingTrack()
+	if playingTrack == nil {
+		return ErrNotPlaying
 	}
 
-	switch manager.RepeatingMode {
-	case RepeatingModeOff, RepeatingModeSong:
+	switch manager.mode() {
+	case RepeatingModeOff, RepeatingModeSong, RepeatingModeSection:
+		manager.stopSectionLoop()
 		if nextTrack := manager.PopQueue(); nextTrack != nil {
 			if err := manager.Player.Play(nextTrack); err != nil {
 				Logger.Warn("Error playing next track: ", err)
-				return err
+				return lavalinkError("play", err)
 			}
 		} else {
 			if err := manager.Player.Stop(); err != nil {
 				Logger.Warn("Error stopping player: ", err)
-				return err
+				return lavalinkError("stop", err)
 			}
-			if err := s.UpdateGameStatus(0, ""); err != nil {
-				Logger.Warn("Error updating status: ", err)
+			if manager.autoplayEnabled() {
+				manager.autoplay(playingTrack)
 			}
 		}
 
 	case RepeatingModeQueue:
-		manager.AddQueue(manager.Player.PlayingTrack().Clone())
+		manager.AddQueue(playingTrack.Clone())
 		if nextTrack := manager.PopQueue(); nextTrack != nil {
 			if err := manager.Player.Play(nextTrack); err != nil {
 				Logger.Warn("Error playing next track: ", err)
-				return err
+				return lavalinkError("play", err)
 			}
 		}
 	}
@@ -217,9 +445,9 @@ func (b *Bot) skip(s *discordgo.Session, guildID string) error {
 }
 
 func (b *Bot) IsQueueEmpty(guildID string) (bool, error) {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return true, errors.New("no player manager available. Connect the bot first")
+		return true, ErrNoPlayer
 	}
 
 	if track := manager.PeekQueue(); track != nil {
@@ -230,27 +458,27 @@ func (b *Bot) IsQueueEmpty(guildID string) (bool, error) {
 }
 
 func (b *Bot) IsPlaying(guildID string) (bool, error) {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return false, errors.New("no player manager available. Connect the bot first")
+		return false, ErrNoPlayer
 	}
 
 	return manager.isPlaying(), nil
 }
 
 func (b *Bot) getTracks(guildID string) ([]lavalink.AudioTrack, error) {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return nil, errors.New("no player manager available. Connect the bot first")
+		return nil, ErrNoPlayer
 	}
 
 	return manager.getAllTracks(), nil
 }
 
 func (b *Bot) setMode(guildID string, mode string) error {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return errors.New("no player manager available. Connect the bot first")
+		return ErrNoPlayer
 	}
 
 	switch mode {
@@ -261,48 +489,212 @@ func (b *Bot) setMode(guildID string, mode string) error {
 	case "all":
 		manager.setMode(RepeatingModeQueue)
 	default:
-		return errors.New("entered unsupported mode")
+		return ErrUnsupportedMode
 	}
 
 	return nil
 }
 
+func (b *Bot) loopSection(guildID string, start lavalink.Duration, end lavalink.Duration) error {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		return ErrNoPlayer
+	}
+
+	track := manager.Player.PlayingTrack()
+	if track == nil || !manager.isPlaying() {
+		return ErrNotPlaying
+	}
+	if track.Info().IsStream {
+		return ErrStreamSection
+	}
+	if end > track.Info().Length {
+		end = track.Info().Length
+	}
+	if start >= end {
+		return ErrSectionOrder
+	}
+
+	Logger.Debug("Looping section ", start, " to ", end)
+	if position := manager.Player.Position(); position < start || position >= end {
+		if err := manager.Player.Seek(start); err != nil {
+			return lavalinkError("seek", err)
+		}
+	}
+	manager.startSectionLoop(track, start, end)
+
+	return nil
+}
+
+// Autoplay is a guild setting, so it can be changed before the bot joins and persists across sessions.
+func (b *Bot) setAutoplay(guildID string, enabled bool) error {
+	return b.Settings.Set(guildID, "autoplay", strconv.FormatBool(enabled))
+}
+
+// Seeks the playing track to the position, or by the position from the current one if relative.
+// Positions outside of the track are clamped to it. Returns the position seeked to.
+func (b *Bot) seekPlaying(guildID string, position lavalink.Duration, relative bool) (lavalink.Duration, error) {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		return 0, ErrNoPlayer
+	}
+
+	track := manager.Player.PlayingTrack()
+	if track == nil || !manager.isPlaying() {
+		return 0, ErrNotPlaying
+	}
+	if relative {
+		position += manager.Player.Position()
+	}
+	if position > track.Info().Length {
+		position = track.Info().Length
+	} else if position < 0 {
+		position = 0
+	}
+
+	return position, b.seek(guildID, position)
+}
+
 func (b *Bot) seek(guildID string, position lavalink.Duration) error {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return errors.New("no player manager available. Connect the bot first")
+		return ErrNoPlayer
 	}
 
 	Logger.Debug("Seeking position: ", position)
 	if err := manager.Player.Seek(position); err != nil {
-		return err
+		return lavalinkError("seek", err)
 	}
+	positionMs := position.Milliseconds()
+	manager.emit(PlayerEvent{Type: EventSeek, Track: playerTrackState(manager.Player), PositionMs: &positionMs})
 
 	return nil
 }
 
+func (b *Bot) pause(guildID string, paused bool) error {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		return ErrNoPlayer
+	}
+
+	return lavalinkError("pause", manager.Player.Pause(paused))
+}
+
+// Loads the tracks of a query without asking the user. Searches pick the first result.
+func (b *Bot) loadTracks(ctx context.Context, guildID string, origin string, query string) ([]lavalink.AudioTrack, error) {
+	if !urlPattern.MatchString(query) {
+		query = lavalink.SearchType(b.Settings.Get(guildID).SearchSource).Apply(query)
+	}
+
+	var tracks []lavalink.AudioTrack
+	var loadErr error
+	if err := loadItemTimed(ctx, b.Lavalink, origin, query, lavalink.NewResultHandler(
+		func(track lavalink.AudioTrack) {
+			setStartPosition(track, urlTimestamp(query))
+			tracks = []lavalink.AudioTrack{track}
+		},
+		func(playlist lavalink.AudioPlaylist) {
+			tracks = playlist.Tracks()
+		},
+		func(results []lavalink.AudioTrack) {
+			tracks = results[:1]
+		},
+		func() {
+			loadErr = fmt.Errorf("%w for %s", ErrNoMatches, query)
+		},
+		func(ex lavalink.FriendlyException) {
+			loadErr = &LavalinkError{Op: "load", Err: errors.New("could not load " + query + ": " + ex.Message)}
+		},
+	)); err != nil {
+		return nil, lavalinkError("load", err)
+	}
+
+	return tracks, loadErr
+}
+
 func (b *Bot) playingTrack(guildID string) (lavalink.AudioTrack, error) {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return nil, errors.New("no player manager available. Connect the bot first")
+		return nil, ErrNoPlayer
 	}
 
 	return manager.Player.PlayingTrack(), nil
 }
 
 func (b *Bot) currentPosition(guildID string) (lavalink.Duration, error) {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return lavalink.Duration(-1), errors.New("no player manager available. Connect the bot first")
+		return lavalink.Duration(-1), ErrNoPlayer
 	}
 
 	return manager.Player.Position(), nil
 }
 
+// Estimated time until each queued track plays. Times are -1 while the playing track repeats and after streams.
+func (b *Bot) queueETAs(guildID string) ([]lavalink.Duration, error) {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		return nil, ErrNoPlayer
+	}
+	return manager.queueETAs(manager.getAllTracks()), nil
+}
+
+func (m *PlayerManager) queueETAs(queue []lavalink.AudioTrack) []lavalink.Duration {
+	var eta lavalink.Duration
+	if mode := m.mode(); mode == RepeatingModeSong || mode == RepeatingModeSection {
+		eta = -1
+	} else if track := m.Player.PlayingTrack(); track != nil && m.isPlaying() {
+		eta = remainingTime(track, m.Player.Position())
+	}
+
+	var etas []lavalink.Duration
+	for _, track := range queue {
+		etas = append(etas, eta)
+		if remaining := remainingTime(track, track.Info().Position); eta < 0 || remaining < 0 {
+			eta = -1
+		} else {
+			eta += remaining
+		}
+	}
+	return etas
+}
+
+// Position of the last queued track with the identifier and the estimated time until it plays.
+// The playing track is at position 0, tracks that are neither queued nor playing at -1.
+func (b *Bot) queueSlot(guildID string, identifier string) (int, lavalink.Duration, error) {
+	manager, ok := b.manager(guildID)
+	if !ok {
+		return -1, -1, ErrNoPlayer
+	}
+
+	queue := manager.getAllTracks()
+	etas := manager.queueETAs(queue)
+	for n := len(queue) - 1; n >= 0; n-- {
+		if queue[n].Info().Identifier == identifier {
+			return n + 1, etas[n], nil
+		}
+	}
+	if track := manager.Player.PlayingTrack(); track != nil && track.Info().Identifier == identifier {
+		return 0, 0, nil
+	}
+	return -1, -1, nil
+}
+
+// Time until the track ends when played from the position, -1 for streams.
+func remainingTime(track lavalink.AudioTrack, position lavalink.Duration) lavalink.Duration {
+	if track.Info().IsStream {
+		return -1
+	}
+	if remaining := track.Info().Length - position; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 func (b *Bot) purgeQueue(guildID string) error {
-	manager, ok := b.PlayerManagers[guildID]
+	manager, ok := b.manager(guildID)
 	if !ok {
-		return errors.New("no player manager available. Connect the bot first")
+		return ErrNoPlayer
 	}
 
 	manager.DeleteQueue()
@@ -310,8 +702,18 @@ func (b *Bot) purgeQueue(guildID string) error {
 }
 
 func (b *Bot) registerNode(conf Configuration) {
+	node, err := b.addNode(conf)
+	if err != nil {
+		Logger.Fatal("Failed to initialized lavalink node: ", err)
+	}
+	if node == nil {
+		Logger.Fatal("Cannot establish connection to lavalink node: ", node)
+	}
+}
+
+func (b *Bot) addNode(conf Configuration) (lavalink.Node, error) {
 	//TODO w\o resume key
-	node, err := b.Link.AddNode(context.TODO(), lavalink.NodeConfig{
+	return b.Link.AddNode(context.TODO(), lavalink.NodeConfig{
 		Name:        conf.LavalinkNode,
 		Host:        conf.LavalinkHost,
 		Port:        conf.LavalinkPort,
@@ -319,143 +721,36 @@ func (b *Bot) registerNode(conf Configuration) {
 		Secure:      conf.Secure,
 		ResumingKey: conf.ResumeKey,
 	})
-
-	if err != nil {
-		Logger.Fatal("Failed to initialized lavalink node: ", err)
-	}
-	if node == nil {
-		Logger.Fatal("Cannot establish connection to lavalink node: ", node)
-	}
 }
 
-func (b *Bot) findChannelQueryUser(s *discordgo.Session, i *discordgo.InteractionCreate, userID string) (*discordgo.VoiceState, error) {
-	guild, err := s.State.Guild(i.GuildID)
+func (b *Bot) findChannelQueryUser(s Session, i *discordgo.InteractionCreate, userID string) (*discordgo.VoiceState, error) {
+	voiceState, err := s.VoiceState(i.GuildID, userID)
 	if err != nil {
-		return nil, err
-	}
-
-	for _, vs := range guild.VoiceStates {
-		if vs.UserID == userID {
-			return vs, nil
-		}
+		return nil, fmt.Errorf("%w: %v", ErrUserNotInVoice, err)
 	}
-
-	return nil, errors.New("could not find user's voice state")
+	return voiceState, nil
 }
 
 func (b *Bot) createCommands(s *discordgo.Session) {
-	// Register commands for all guilds
-	// play command
-	playCmd := discordgo.ApplicationCommand{
-		Name:        "play",
-		Description: "Play a query song.",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "query",
-				Description: "Song query that should be played.",
-				Required:    true,
-			},
-		},
-	}
-
-	// leave command
-	leaveCmd := discordgo.ApplicationCommand{
-		Name:        "leave",
-		Description: "Leave the current voice channel.",
-	}
-
-	// skip command
-	skipCmd := discordgo.ApplicationCommand{
-		Name:        "skip",
-		Description: "Skip one or all songs.",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "single",
-				Description: "Skip the currently playing song.",
-			},
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "all",
-				Description: "Skip all songs in the playlist.",
-			},
-		},
-	}
-
-	// playlist command
-	playlistCmd := discordgo.ApplicationCommand{
-		Name:        "show",
-		Description: "Display the current playlist.",
-	}
-
-	// play mode command
-	setCmd := discordgo.ApplicationCommand{
-		Name:        "set",
-		Description: "Set the play mode.",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "off",
-				Description: "No play mode.",
-			},
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "single",
-				Description: "Single repeat mode.",
-			},
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "all",
-				Description: "All repeat mode.",
-			},
-		},
-	}
-
-	// seek command
-	seekCmd := discordgo.ApplicationCommand{
-		Name:        "seek",
-		Description: "Jump to a position in a song (in seconds).",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "relative",
-				Description: "Jump to relative position from current position (in seconds).",
-				Options: []*discordgo.ApplicationCommandOption{
-					{
-						Type:        discordgo.ApplicationCommandOptionInteger,
-						Name:        "position",
-						Description: "Relative position (integer).",
-						Required:    true,
-					},
-				},
-			},
-			{
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
-				Name:        "absolute",
-				Description: "Jump to absolute position (in seconds).",
-				Options: []*discordgo.ApplicationCommandOption{
-					{
-						Type:        discordgo.ApplicationCommandOptionInteger,
-						Name:        "position",
-						Description: "Absolute position (integer).",
-						Required:    true,
-					},
-				},
-			},
-		},
+	// Register commands instantly in development guilds instead of for all guilds
+	for _, guildID := range commandGuilds(b.config()) {
+		if err := syncCommands(s, b.Link.UserID().String(), guildID, ApplicationCommands()); err != nil {
+			Logger.Warn("Failed to register commands ", commandScope(guildID), ": ", err)
+		}
 	}
+}
 
-	// exit command
-	exitCmd := discordgo.ApplicationCommand{
-		Name:        "exit",
-		Description: "Bot program termination.",
+// Guilds commands are registered in. The empty guild ID registers commands globally.
+func commandGuilds(conf Configuration) []string {
+	if len(conf.DevGuildIDs) > 0 {
+		return conf.DevGuildIDs
 	}
-	// TODO set permission for command
+	return []string{""}
+}
 
-	allCmds := []*discordgo.ApplicationCommand{&playCmd, &leaveCmd, &skipCmd, &playlistCmd, &setCmd, &seekCmd, &exitCmd}
-	if _, err := s.ApplicationCommandBulkOverwrite(b.Link.UserID().String(), "", allCmds); err != nil {
-		Logger.Panic("Failed to overwrite commands: ", err)
-		// TODO may need to create commands if not created on server
+func commandScope(guildID string) string {
+	if guildID == "" {
+		return "globally"
 	}
+	return "for guild " + guildID
 }
@@ -0,0 +1,625 @@
+package gobot
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Plays the tracks in the test guild as if they were queued with the play command.
+func playTracks(t *testing.T, bot *Bot, tracks ...lavalink.AudioTrack) *fakePlayer {
+	t.Helper()
+	if err := bot.Play(bot.Session, commandInteraction("play"), tracks...); err != nil {
+		t.Fatalf("playing tracks: %v", err)
+	}
+	return bot.PlayerManagers[testGuildID].Player.(*fakePlayer)
+}
+
+func TestPlayCommand(t *testing.T) {
+	bot, session, link := newTestBot(t)
+	first := testTrack("first", "First", 3*lavalink.Minute)
+	second := testTrack("second", "Second", 4*lavalink.Minute)
+	link.tracks["https://example.com/first"] = []lavalink.AudioTrack{first}
+	link.tracks["ytsearch:second"] = []lavalink.AudioTrack{second}
+
+	playCommand(session, commandInteraction("play", stringOption("query", "https://example.com/first")), bot)
+
+	if got := session.lastFollowup(t).Content; !strings.HasPrefix(got, "Adding the song to queue") {
+		t.Errorf("unexpected follow up: %q", got)
+	}
+	if state, _ := session.VoiceState(testGuildID, testBotID); state == nil || state.ChannelID != testChannelID {
+		t.Errorf("bot did not join the voice channel of the user: %+v", state)
+	}
+	player := link.players[testGuildID]
+	if player == nil || player.PlayingTrack() != first {
+		t.Fatalf("first track is not playing")
+	}
+	if len(player.listeners) != 1 {
+		t.Errorf("expected the player manager as listener, got %d listeners", len(player.listeners))
+	}
+
+	// Searches with a single result are queued behind the playing track
+	playCommand(session, commandInteraction("play", stringOption("query", "second")), bot)
+
+	if player.PlayingTrack() != first {
+		t.Errorf("playing track changed to %q", player.PlayingTrack().Info().Title)
+	}
+	if queue := bot.PlayerManagers[testGuildID].Queue; len(queue) != 1 || queue[0] != second {
+		t.Errorf("expected second track in queue, got %v", queue)
+	}
+}
+
+func TestPlayCommandStart(t *testing.T) {
+	bot, session, link := newTestBot(t)
+	link.tracks["https://example.com/track"] = []lavalink.AudioTrack{testTrack("track", "Track", 3*lavalink.Minute)}
+
+	playCommand(session, commandInteraction("play",
+		stringOption("query", "https://example.com/track"),
+		stringOption("start", "1:30"),
+	), bot)
+
+	if position := link.players[testGuildID].Position(); position != 90*lavalink.Second {
+		t.Errorf("expected track to start at 1:30, got %s", formatTimestamp(position))
+	}
+}
+
+func TestPlayCommandSearchResults(t *testing.T) {
+	bot, session, link := newTestBot(t)
+	var results []lavalink.AudioTrack
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		results = append(results, testTrack(id, "Result "+id, lavalink.Minute))
+	}
+	link.tracks["ytsearch:query"] = results
+
+	playCommand(session, commandInteraction("play", stringOption("query", "query")), bot)
+
+	if got := session.lastFollowup(t); len(got.Components) != 1 {
+		t.Fatalf("expected a select menu, got %+v", got)
+	}
+	if choices := bot.TrackMap[testUserID]; len(choices) != 5 {
+		t.Errorf("expected 5 choices for the user, got %d", len(choices))
+	}
+	if _, ok := bot.PlayerManagers[testGuildID]; ok {
+		t.Error("player was created before a track was selected")
+	}
+}
+
+func TestPlayCommandErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		inVoice  bool
+		response string
+	}{
+		{name: "no matches", query: "nothing", inVoice: true, response: "No matches found for your query."},
+		{name: "user not in voice", query: "https://example.com/track", response: "Please join a voice channel first."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, link := newTestBot(t)
+			link.tracks["https://example.com/track"] = []lavalink.AudioTrack{testTrack("track", "Track", lavalink.Minute)}
+			if !test.inVoice {
+				session.leaveVoice(testGuildID, testUserID)
+			}
+
+			playCommand(session, commandInteraction("play", stringOption("query", test.query)), bot)
+
+			if got := session.lastFollowup(t).Content; got != test.response {
+				t.Errorf("expected %q, got %q", test.response, got)
+			}
+			if state, _ := session.VoiceState(testGuildID, testBotID); state != nil {
+				t.Error("bot joined a voice channel")
+			}
+		})
+	}
+}
+
+func TestPlayCommandMissingQuery(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	playCommand(session, commandInteraction("play"), bot)
+
+	if got := session.lastResponse(t); got != "Please enter a query or attach an audio file." {
+		t.Errorf("unexpected response: %q", got)
+	}
+}
+
+func TestSkipCommand(t *testing.T) {
+	first := testTrack("first", "First", lavalink.Minute)
+	second := testTrack("second", "Second", lavalink.Minute)
+	third := testTrack("third", "Third", lavalink.Minute)
+
+	tests := []struct {
+		name    string
+		option  string
+		mode    RepeatingMode
+		playing lavalink.AudioTrack
+		queue   int
+	}{
+		{name: "single", option: "single", playing: second, queue: 1},
+		{name: "all", option: "all", playing: nil, queue: 0},
+		{name: "single repeating queue", option: "single", mode: RepeatingModeQueue, playing: second, queue: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, _ := newTestBot(t)
+			player := playTracks(t, bot, first, second, third)
+			bot.PlayerManagers[testGuildID].setMode(test.mode)
+
+			skipCommand(session, commandInteraction("skip", subcommand(test.option)), bot)
+
+			if got := session.lastResponse(t); got != "Skipping song(s). 🤫" {
+				t.Errorf("unexpected response: %q", got)
+			}
+			if player.PlayingTrack() != test.playing {
+				t.Errorf("expected %v to play, got %v", test.playing, player.PlayingTrack())
+			}
+			if queue := bot.PlayerManagers[testGuildID].Queue; len(queue) != test.queue {
+				t.Errorf("expected %d queued tracks, got %d", test.queue, len(queue))
+			}
+		})
+	}
+}
+
+func TestSkipCommandNotPlaying(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	skipCommand(session, commandInteraction("skip", subcommand("single")), bot)
+	if got := session.lastResponse(t); got != "I'm not connected. Why would you do that? 😢" {
+		t.Errorf("unexpected response without player: %q", got)
+	}
+
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+	if err := player.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	skipCommand(session, commandInteraction("skip", subcommand("single")), bot)
+	if got := session.lastResponse(t); got != "There are no songs to skip. Why would you do that? 😢" {
+		t.Errorf("unexpected response without playing track: %q", got)
+	}
+}
+
+func TestSeekCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   string
+		value    string
+		position lavalink.Duration
+		response string
+	}{
+		{name: "absolute", option: "absolute", value: "1:30", position: 90 * lavalink.Second,
+			response: "Seeking absolute position 1:30 in song. 🤫"},
+		{name: "absolute past end", option: "absolute", value: "1h", position: 3 * lavalink.Minute,
+			response: "Seeking absolute position 3:00 in song. 🤫"},
+		{name: "relative forward", option: "relative", value: "30", position: 90 * lavalink.Second,
+			response: "Seeking relative position 1:30 in song. 🤫"},
+		{name: "relative backward", option: "relative", value: "-2m", position: 0,
+			response: "Seeking relative position 0:00 in song. 🤫"},
+		{name: "invalid", option: "absolute", value: "soon", position: lavalink.Minute,
+			response: "Unsupported position. Use a format like 90, 1:23 or 1h2m3s."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, _ := newTestBot(t)
+			player := playTracks(t, bot, testTrack("track", "Track", 3*lavalink.Minute))
+			player.position = lavalink.Minute
+
+			seekCommand(session, commandInteraction("seek", subcommand(test.option, stringOption("position", test.value))), bot)
+
+			if got := session.lastResponse(t); got != test.response {
+				t.Errorf("expected %q, got %q", test.response, got)
+			}
+			if player.Position() != test.position {
+				t.Errorf("expected position %s, got %s", formatTimestamp(test.position), formatTimestamp(player.Position()))
+			}
+		})
+	}
+}
+
+func TestSeekCommandNotConnected(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	seekCommand(session, commandInteraction("seek", subcommand("absolute", stringOption("position", "10"))), bot)
+
+	if got := session.lastResponse(t); got != "I'm not connected. Why would you do that? 😢" {
+		t.Errorf("unexpected response: %q", got)
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	track := testTrack("track", "Track", lavalink.Minute)
+	next := testTrack("next", "Next", lavalink.Minute)
+
+	tests := []struct {
+		mode    string
+		want    RepeatingMode
+		playing lavalink.AudioTrack // playing track once the first track finished
+	}{
+		{mode: "off", want: RepeatingModeOff, playing: next},
+		{mode: "single", want: RepeatingModeSong, playing: track},
+		{mode: "all", want: RepeatingModeQueue, playing: next},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			bot, session, _ := newTestBot(t)
+			player := playTracks(t, bot, track, next)
+
+			setCommand(session, commandInteraction("set", subcommand(test.mode)), bot)
+
+			if got := session.lastResponse(t); got != "Set mode to: "+test.mode {
+				t.Errorf("unexpected response: %q", got)
+			}
+			manager := bot.PlayerManagers[testGuildID]
+			if manager.RepeatingMode != test.want {
+				t.Errorf("expected mode %d, got %d", test.want, manager.RepeatingMode)
+			}
+
+			manager.OnTrackEnd(nil, track, lavalink.AudioTrackEndReasonFinished)
+			manager.stopIdleTimer()
+			if playing := player.PlayingTrack(); playing == nil || playing.Info().Identifier != test.playing.Info().Identifier {
+				t.Errorf("expected %q to play after the track finished, got %v", test.playing.Info().Title, playing)
+			}
+		})
+	}
+}
+
+func TestSetCommandNotConnected(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	setCommand(session, commandInteraction("set", subcommand("single")), bot)
+
+	if got := session.lastResponse(t); got != "I'm not connected. Why would you do that? 😢" {
+		t.Errorf("unexpected response: %q", got)
+	}
+}
+
+func TestSectionLoopModeChanges(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	next := testTrack("next", "Next", lavalink.Minute)
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute), next, testTrack("last", "Last", lavalink.Minute))
+	manager := bot.PlayerManagers[testGuildID]
+
+	modes := make(chan string, 16)
+	bot.Bus.Subscribe("test", func(event interface{}) {
+		if playerEvent, ok := event.(PlayerEvent); ok && playerEvent.Type == EventModeChanged {
+			modes <- playerEvent.Mode
+		}
+	})
+	expectMode := func(mode RepeatingMode) {
+		t.Helper()
+		select {
+		case name := <-modes:
+			if name != repeatingModeNames[mode] {
+				t.Errorf("expected mode %q, got %q", repeatingModeNames[mode], name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s mode event published", repeatingModeNames[mode])
+		}
+		if got := manager.mode(); got != mode {
+			t.Errorf("expected mode %d, got %d", mode, got)
+		}
+	}
+
+	if err := bot.loopSection(testGuildID, 10*lavalink.Second, 20*lavalink.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectMode(RepeatingModeSection)
+
+	// The section loop reads the player under SectionMu
+	manager.SectionMu.Lock()
+	if err := player.Play(next); err != nil {
+		t.Fatal(err)
+	}
+	manager.SectionMu.Unlock()
+	expectMode(RepeatingModeOff)
+
+	if err := bot.loopSection(testGuildID, 10*lavalink.Second, 20*lavalink.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectMode(RepeatingModeSection)
+	if err := bot.skip(bot.Session, testGuildID); err != nil {
+		t.Fatal(err)
+	}
+	expectMode(RepeatingModeOff)
+}
+
+func TestAutoplayCommand(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	// Autoplay is a guild setting, so it can be enabled before the bot joins
+	autoplayCommand(session, commandInteraction("autoplay", subcommand("on")), bot)
+	if got := session.lastResponse(t); got != "Set autoplay to: on" {
+		t.Errorf("unexpected response without player: %q", got)
+	}
+	playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+	manager := bot.PlayerManagers[testGuildID]
+	if !newPlayerState(manager).Autoplay {
+		t.Error("expected autoplay to be on after joining")
+	}
+
+	// The admin API and dashboards read autoplay while members change it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 100; n++ {
+			newPlayerState(manager)
+		}
+	}()
+	for _, mode := range []string{"off", "on"} {
+		autoplayCommand(session, commandInteraction("autoplay", subcommand(mode)), bot)
+		if got := session.lastResponse(t); got != "Set autoplay to: "+mode {
+			t.Errorf("unexpected response: %q", got)
+		}
+	}
+	<-done
+
+	store, err := NewGuildSettingsStore(bot.Settings.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !store.Get(testGuildID).Autoplay {
+		t.Error("expected autoplay to be persisted")
+	}
+}
+
+func TestShowCommand(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	var tracks []lavalink.AudioTrack
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		tracks = append(tracks, testTrack(id, "Track "+id, lavalink.Minute))
+	}
+	playTracks(t, bot, tracks...)
+
+	showCommand(session, commandInteraction("show"), bot)
+
+	followup := session.lastFollowup(t)
+	if len(followup.Embeds) != 2 {
+		t.Fatalf("expected the playing track and the queue, got %d embeds", len(followup.Embeds))
+	}
+	if playing := followup.Embeds[0]; playing.Author.Name != "Now playing" || playing.Title != "Track a" {
+		t.Errorf("unexpected playing track embed: %q %q", playing.Author.Name, playing.Title)
+	}
+	fields := followup.Embeds[1].Fields
+	if len(fields) != 5 {
+		t.Fatalf("expected 5 queued tracks, got %d fields", len(fields))
+	}
+	if fields[0].Value != "Track b (1:00) · plays in 1:00" || fields[4].Value != "Track f (1:00) · plays in 5:00" {
+		t.Errorf("unexpected queue fields: %q to %q", fields[0].Value, fields[4].Value)
+	}
+}
+
+func TestShowCommandEmpty(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+
+	showCommand(session, commandInteraction("show"), bot)
+	if got := session.lastFollowup(t).Content; !strings.HasPrefix(got, "An error occurred trying to display playlist.") {
+		t.Errorf("unexpected response without player: %q", got)
+	}
+
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+	if err := player.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	showCommand(session, commandInteraction("show"), bot)
+	if got := session.lastFollowup(t).Content; got != "Playlist is empty." {
+		t.Errorf("unexpected response for empty playlist: %q", got)
+	}
+}
+
+func TestLeaveCommand(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+
+	leaveCommand(session, commandInteraction("leave"), bot)
+
+	if got := session.lastResponse(t); got != "行ってきます、ご主人様" {
+		t.Errorf("unexpected response: %q", got)
+	}
+	if state, _ := session.VoiceState(testGuildID, testBotID); state != nil {
+		t.Error("bot is still in the voice channel")
+	}
+	if !player.destroyed || player.PlayingTrack() != nil {
+		t.Error("player was not stopped and destroyed")
+	}
+	if _, ok := bot.PlayerManagers[testGuildID]; ok {
+		t.Error("player manager was not removed")
+	}
+
+	leaveCommand(session, commandInteraction("leave"), bot)
+	if got := session.lastResponse(t); !strings.HasPrefix(got, "I'm not connected to any voice channel.") {
+		t.Errorf("unexpected response when not connected: %q", got)
+	}
+}
+
+// Run with -race: the idle timer leaves on its own goroutine while HTTP handlers read the managers.
+func TestConcurrentIdleLeave(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+	manager := bot.PlayerManagers[testGuildID]
+
+	var wg sync.WaitGroup
+	for n := 0; n < 2; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.OnIdle()
+		}()
+	}
+	for n := 0; n < 100; n++ {
+		bot.managers()
+		bot.manager(testGuildID)
+	}
+	wg.Wait()
+
+	if _, ok := bot.manager(testGuildID); ok {
+		t.Error("idle guild was not left")
+	}
+	if player := manager.Player.(*fakePlayer); !player.destroyed {
+		t.Error("player was not destroyed")
+	}
+}
+
+func TestDJOnlyCommands(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "dj_role", "500"); err != nil {
+		t.Fatal(err)
+	}
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+
+	bot.handleInteraction(commandInteraction("leave"))
+
+	if got := session.lastResponse(t); got != "Only members with the DJ role can do that." {
+		t.Errorf("unexpected response: %q", got)
+	}
+	if player.destroyed {
+		t.Error("member without DJ role made the bot leave")
+	}
+}
+
+func TestExitCommandPermission(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	player := playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+
+	if err := exitCommand(session, commandInteraction("exit"), bot); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected exit without permission to be denied, got %v", err)
+	}
+	if got := session.lastResponse(t); got != "You need the Manage Server permission to shut down the bot." {
+		t.Errorf("unexpected response: %q", got)
+	}
+	if player.destroyed {
+		t.Error("member without permission made the bot leave")
+	}
+}
+
+func TestCommandResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		connected   bool
+		result      string
+	}{
+		{name: "ok", interaction: commandInteraction("leave"), connected: true, result: commandResultOK},
+		{name: "failed", interaction: commandInteraction("set", subcommand("single")), result: commandResultError},
+		{name: "invalid option", interaction: commandInteraction("seek", subcommand("absolute", stringOption("position", "soon"))), connected: true, result: commandResultError},
+		{name: "missing permission", interaction: commandInteraction("settings", subcommand("view")), result: commandResultDenied},
+		{name: "exit without permission", interaction: commandInteraction("exit"), result: commandResultDenied},
+		{name: "unknown", interaction: commandInteraction("dance"), result: commandResultUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, _, _ := newTestBot(t)
+			if test.connected {
+				playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+			}
+			results := make(chan string, 1)
+			bot.Bus.Subscribe("test", func(event interface{}) {
+				if command, ok := event.(CommandEvent); ok {
+					results <- command.Result
+				}
+			})
+
+			bot.handleInteraction(test.interaction)
+
+			select {
+			case result := <-results:
+				if result != test.result {
+					t.Errorf("expected result %q, got %q", test.result, result)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no command event published")
+			}
+		})
+	}
+}
+
+func TestResponseVisibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  map[string]string
+		connected bool
+		public    bool
+	}{
+		{name: "private by default", connected: true, public: false},
+		{name: "public guild", settings: map[string]string{"visibility": "public"}, connected: true, public: true},
+		{name: "public command", settings: map[string]string{"public_commands": "skip, leave"}, connected: true, public: true},
+		{name: "private command", settings: map[string]string{"visibility": "public", "private_commands": "leave"}, connected: true, public: false},
+		{name: "errors stay private", settings: map[string]string{"visibility": "public"}, connected: false, public: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, _ := newTestBot(t)
+			for key, value := range test.settings {
+				if err := bot.Settings.Set(testGuildID, key, value); err != nil {
+					t.Fatalf("setting %s: %v", key, err)
+				}
+			}
+			if test.connected {
+				playTracks(t, bot, testTrack("track", "Track", lavalink.Minute))
+			}
+
+			leaveCommand(session, commandInteraction("leave"), bot)
+
+			response := session.responses[len(session.responses)-1]
+			if public := response.Data.Flags&uint64(discordgo.MessageFlagsEphemeral) == 0; public != test.public {
+				t.Errorf("expected public %v, got flags %d", test.public, response.Data.Flags)
+			}
+		})
+	}
+}
+
+func TestVisibilitySettings(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "public_commands", " play,, Play in voice "); err != nil {
+		t.Fatalf("setting public commands: %v", err)
+	}
+	if got := bot.Settings.Get(testGuildID).PublicCommands; got != "play,Play in voice" {
+		t.Errorf("public commands were not normalized: %q", got)
+	}
+	if err := bot.Settings.Set(testGuildID, "private_commands", "play,unknown"); err == nil {
+		t.Error("unknown command was accepted")
+	}
+
+	// Settings show admin values and stay private even if listed
+	if err := bot.Settings.Set(testGuildID, "public_commands", "settings"); err != nil {
+		t.Fatalf("setting public commands: %v", err)
+	}
+	if bot.public(commandInteraction("settings")) {
+		t.Error("settings command responds publicly")
+	}
+}
+
+func TestQueuedAnnouncement(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "announce_channel", "600"); err != nil {
+		t.Fatal(err)
+	}
+	event := QueuedEvent{GuildID: testGuildID, UserID: testUserID, User: "tester", Titles: []string{"First"}}
+
+	bot.announceQueued(event)
+	if len(session.messages["600"]) != 0 {
+		t.Errorf("announced queued songs without announce_queued: %v", session.messages["600"])
+	}
+
+	if err := bot.Settings.Set(testGuildID, "announce_queued", "true"); err != nil {
+		t.Fatal(err)
+	}
+	bot.announceQueued(event)
+	event.Titles = append(event.Titles, "Second")
+	bot.announceQueued(event)
+
+	expected := []string{"tester added First to the queue.", "tester added 2 songs to the queue."}
+	if got := session.messages["600"]; strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected announcements %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,76 @@
+package gobot
+
+import (
+	"sync"
+	"time"
+)
+
+// Events buffered per subscriber before further events are dropped for it
+const busBufferSize = 256
+
+// Internal pub/sub bus. Players and command handlers publish events, side effects like status updates,
+// announcements and the event stream subscribe to them. Events are PlayerEvent, CommandEvent or QueuedEvent values.
+//
+// Every subscriber receives the events in publishing order on its own goroutine,
+// so publishing never blocks and a slow subscriber does not delay the others.
+// Delivery is best effort, so state that has to be exact like metrics must not depend on it.
+type EventBus struct {
+	subscribers   []*busSubscriber
+	subscribersMu sync.Mutex
+}
+
+type busSubscriber struct {
+	name   string
+	events chan interface{}
+}
+
+// Result of a command handled by the interaction dispatcher.
+type CommandEvent struct {
+	Command string
+	GuildID string
+	UserID  string
+	Result  string
+	Time    time.Time
+}
+
+// Tracks a member added to the queue with a command.
+type QueuedEvent struct {
+	GuildID string
+	UserID  string
+	User    string // name of the member shown in announcements
+	Titles  []string
+	Time    time.Time
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Calls the handler for every event published from now on.
+func (b *EventBus) Subscribe(name string, handler func(event interface{})) {
+	subscriber := &busSubscriber{
+		name:   name,
+		events: make(chan interface{}, busBufferSize),
+	}
+	go func() {
+		for event := range subscriber.events {
+			handler(event)
+		}
+	}()
+
+	b.subscribersMu.Lock()
+	defer b.subscribersMu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+func (b *EventBus) Publish(event interface{}) {
+	b.subscribersMu.Lock()
+	defer b.subscribersMu.Unlock()
+	for _, subscriber := range b.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			Logger.Warn("Event subscriber ", subscriber.name, " is too slow. Dropping event.")
+		}
+	}
+}
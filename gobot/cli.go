@@ -0,0 +1,119 @@
+package gobot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Version of the exported state format
+const stateVersion = 1
+
+// Persisted bot data for export-state and import-state.
+type State struct {
+	Version int                      `json:"version"`
+	Guilds  map[string]GuildSettings `json:"guilds"`
+}
+
+// Creates a discord session for REST calls only and returns it with the application ID.
+// The gateway is not opened, so running bots are not affected.
+func restSession(conf Configuration) (*discordgo.Session, string, error) {
+	s, err := discordgo.New("Bot " + conf.DiscordToken)
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := s.User("@me")
+	if err != nil {
+		return nil, "", fmt.Errorf("could not retrieve bot user: %w", err)
+	}
+	return s, user.ID, nil
+}
+
+// Syncs the application commands globally or for a guild if guildID is set.
+// Only changed commands are updated.
+func RegisterCommands(conf Configuration, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	s, appID, err := restSession(conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncCommands(s, appID, guildID, ApplicationCommands()); err != nil {
+		return nil, err
+	}
+	return s.ApplicationCommands(appID, guildID)
+}
+
+// Removes all application commands globally or for a guild if guildID is set.
+func UnregisterCommands(conf Configuration, guildID string) error {
+	s, appID, err := restSession(conf)
+	if err != nil {
+		return err
+	}
+	_, err = s.ApplicationCommandBulkOverwrite(appID, guildID, []*discordgo.ApplicationCommand{})
+	return err
+}
+
+// Lists the registered application commands globally or for a guild if guildID is set.
+func ListCommands(conf Configuration, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	s, appID, err := restSession(conf)
+	if err != nil {
+		return nil, err
+	}
+	return s.ApplicationCommands(appID, guildID)
+}
+
+// Writes all persisted data as JSON.
+func ExportState(conf Configuration, out io.Writer) error {
+	settings, err := NewGuildSettingsStore(conf.GuildSettingsFile)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(State{
+		Version: stateVersion,
+		Guilds:  settings.Guilds,
+	})
+}
+
+// Replaces the persisted data with a previously exported state.
+// The bot should not be running since it would overwrite the imported data.
+func ImportState(conf Configuration, in io.Reader) error {
+	var state struct {
+		Version int                        `json:"version"`
+		Guilds  map[string]json.RawMessage `json:"guilds"`
+	}
+	if err := json.NewDecoder(in).Decode(&state); err != nil {
+		return fmt.Errorf("could not parse state: %w", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported state version %d", state.Version)
+	}
+
+	// Guilds may use custom themes
+	if err := Messages.LoadCustomThemes(conf.ThemeDir); err != nil {
+		return fmt.Errorf("could not load custom themes: %w", err)
+	}
+
+	settings := &GuildSettingsStore{
+		File:   conf.GuildSettingsFile,
+		Guilds: map[string]GuildSettings{},
+	}
+	for guildID, raw := range state.Guilds {
+		guild, err := decodeGuildSettings(raw)
+		if err != nil {
+			return fmt.Errorf("could not parse settings for guild %s: %w", guildID, err)
+		}
+		if problems := validateFields(reflect.ValueOf(guild), guildSettingLabel); len(problems) > 0 {
+			return errors.New("invalid settings for guild " + guildID + ": " + strings.Join(problems, "; "))
+		}
+		settings.Guilds[guildID] = guild
+	}
+
+	return settings.save()
+}
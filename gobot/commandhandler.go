@@ -2,88 +2,185 @@ package gobot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/disgolink/lavalink"
 	"github.com/sirupsen/logrus"
 )
 
-var CommandsHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot){
-	"play":  playCommand,
-	"leave": leaveCommand,
-	"skip":  skipCommand,
-	"show":  showCommand,
-	"set":   setCommand,
-	"seek":  seekCommand,
-	"exit":  exitCommand,
+// Handlers of the application commands. They respond to the interaction themselves and return the error
+// that made the command fail, which is recorded in the command metrics.
+var CommandsHandlers = map[string]func(s Session, i *discordgo.InteractionCreate, b *Bot) error{
+	"play":      playCommand,
+	"leave":     leaveCommand,
+	"skip":      skipCommand,
+	"show":      showCommand,
+	"set":       setCommand,
+	"seek":      seekCommand,
+	"loop":      loopCommand,
+	"autoplay":  autoplayCommand,
+	"library":   libraryCommand,
+	"settings":  settingsCommand,
+	"dashboard": dashboardCommand,
+	"exit":      exitCommand,
+
+	// Message context menu commands
+	"Play in voice": playMessageCommand,
 }
 
-func playCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
-	// Get input string from play command
-	data := i.ApplicationCommandData().Options[0]
-	if data == nil {
-		Logger.Warn("Expected user query but options are empty. Make sure the commands are set up properly.")
-		response := SingleInteractionResponse("An error occurred. The bot command appears to be set up incorrectly. Please try again later.",
+// Commands restricted to the DJ role of a guild
+var DJCommands = map[string]bool{
+	"leave":    true,
+	"skip":     true,
+	"set":      true,
+	"seek":     true,
+	"loop":     true,
+	"autoplay": true,
+}
+
+// Commands that respond privately regardless of the visibility settings, as they show settings or personal links
+var PrivateCommands = map[string]bool{
+	"settings":  true,
+	"dashboard": true,
+}
+
+// Whether successful responses to the interaction are shown to the whole channel. Commands follow the
+// visibility settings of the guild, components keep the visibility of the message they belong to.
+// Errors are always private, except in follow ups of deferred responses, which can't change the visibility.
+func (b *Bot) public(i *discordgo.InteractionCreate) bool {
+	if i.Type == discordgo.InteractionMessageComponent {
+		return i.Message != nil && i.Message.Flags&discordgo.MessageFlagsEphemeral == 0
+	}
+
+	command := i.ApplicationCommandData().Name
+	settings := b.Settings.Get(i.GuildID)
+	switch {
+	case PrivateCommands[command], containsString(commandList(settings.PrivateCommands), command):
+		return false
+	case containsString(commandList(settings.PublicCommands), command):
+		return true
+	}
+	return settings.Visibility == "public"
+}
+
+func djOnlyResponse(s Session, i *discordgo.InteractionCreate, b *Bot) {
+	response := SingleInteractionResponse(b.text(i, "dj_only", nil), discordgo.InteractionResponseChannelMessageWithSource)
+	if err := s.InteractionRespond(i.Interaction, response); err != nil {
+		Logger.Warn("Failed to create interaction response: ", err)
+	}
+}
+
+func playCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	// Get query, attachment and start offset from play command
+	var query, startValue string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "query":
+			query = fmt.Sprintf("%v", option.Value)
+		case "attachment":
+			if attachment, ok := i.ApplicationCommandData().Resolved.Attachments[fmt.Sprintf("%v", option.Value)]; ok {
+				query = attachment.URL
+			}
+		case "start":
+			startValue = option.StringValue()
+		}
+	}
+	if query == "" {
+		Logger.Warn("Expected user query or attachment but options are empty.")
+		response := SingleInteractionResponse(b.text(i, "play.missing_query", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
 		if err := s.InteractionRespond(i.Interaction, response); err != nil {
 			Logger.Warn("Failed to create interaction response: ", err)
 		}
-		return
+		return ErrInvalidOptions
+	}
+
+	// Start offset from the optional start option or a timestamp in the url
+	start := urlTimestamp(query)
+	var startErr error
+	if startValue != "" {
+		start, startErr = parseTimestamp(startValue)
 	}
-	query := fmt.Sprintf("%v", data.Value)
 
 	playLogger := Logger.WithFields(logrus.Fields{
 		"cmd":     "play",
 		"userID":  i.Member.User.ID,
 		"guildID": i.GuildID,
 		"query":   query,
+		"start":   start,
 	})
 	playLogger.Info("Play command selected.")
 
+	if startErr != nil {
+		playLogger.Warn("Could not parse start option: ", startErr)
+		response := SingleInteractionResponse(b.text(i, "play.unsupported_start", nil),
+			discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			playLogger.Warn("Failed to create interaction response: ", err)
+		}
+		return startErr
+	}
+
 	// Defer message since it may take some time to retrieve yt queries
-	deferredResponse := SingleInteractionResponse("Response will soon follow.", discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	public := b.public(i)
+	deferredResponse := NewResponse(b.text(i, "deferred", nil)).Visible(public).Interaction(discordgo.InteractionResponseDeferredChannelMessageWithSource)
 	if err := s.InteractionRespond(i.Interaction, deferredResponse); err != nil {
 		playLogger.Warn("Failed to create deferred response: ", err)
 	}
 
-	// If query is url, add ytsearch for lavalink
+	// If query is not a url, add the search source of the guild for lavalink
 	if !urlPattern.MatchString(query) {
-		query = "ytsearch:" + query
+		query = lavalink.SearchType(b.Settings.Get(i.GuildID).SearchSource).Apply(query)
 	}
 
 	//TODO filter for length > 100
 
 	var response *discordgo.WebhookParams
+	var playErr error
 	// Handle different return values from lavalink and play track(s) ...
-	_ = b.Link.BestRestClient().LoadItemHandler(context.TODO(), query, lavalink.NewResultHandler(
+	loadErr := loadItemTimed(context.TODO(), b.Lavalink, "play", query, lavalink.NewResultHandler(
 		func(track lavalink.AudioTrack) {
 			// Directly queue track if it is a single track
 			playLogger.Debug("Single audio track is returned by lavalink.")
+			setStartPosition(track, start)
 			if err := b.Play(s, i, track); err != nil {
 				playLogger.Warn("Error occurred while trying to play single track: ", err)
-				response = SingleFollowUpResponse("An error occurred trying to play the track " + track.Info().Title + ". Please try again.")
+				playErr = err
+				response = SingleFollowUpResponse(userMessage(b.style(i), err))
 			} else {
 				// Initial response
-				response = SingleButtonFollowUpResponse("Adding the song to queue: "+query, "Link to your song :)", *track.Info().URI, "🤷")
+				response = NewResponse(b.text(i, "play.added", Args{"Query": query})).Embed(b.queuedEmbed(i, track)).
+					Button(b.text(i, "play.song_link", nil), *track.Info().URI, b.text(i, "play.link_emoji", nil)).Visible(public).FollowUp()
 			}
 			if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 				playLogger.Warn("Something went wrong when interacting with play command: ", err)
+				playErr = err
 			}
 		},
 		func(playlist lavalink.AudioPlaylist) {
 			// Directly queue playlist
 			playLogger.Debug("Playlist is returned by lavalink.")
+			if len(playlist.Tracks()) > 0 {
+				setStartPosition(playlist.Tracks()[0], start)
+			}
 			if err := b.Play(s, i, playlist.Tracks()...); err != nil {
 				playLogger.Warn("Error occurred while trying to play single track: ", err)
-				response = SingleFollowUpResponse("An error occurred trying to play the playlist " + playlist.Name() + ". Please try again.")
+				playErr = err
+				response = SingleFollowUpResponse(userMessage(b.style(i), err))
 			} else {
 				// Initial response
-				response = SingleButtonFollowUpResponse("Adding the song to queue: "+query, "Link to your playlist :)", query, "🤷")
+				response = NewResponse(b.text(i, "play.added", Args{"Query": query})).Embed(b.queuedEmbed(i, playlist.Tracks()[0])).
+					Button(b.text(i, "play.playlist_link", nil), query, b.text(i, "play.link_emoji", nil)).Visible(public).FollowUp()
 			}
 			if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 				playLogger.Warn("Something went wrong when interacting with play command: ", err)
+				playErr = err
 			}
 		},
 		func(tracks []lavalink.AudioTrack) {
@@ -104,12 +201,14 @@ func playCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 			// TODO cancel button
 
 			// Follow up on the deferred message
-			response := SingleSelectMenuFollowUpResponse("Please choose a song from the menu.", "selectTrack", "Choose your desired youtube video 👇", options)
+			response := SingleSelectMenuFollowUpResponse(b.text(i, "play.choose", nil), "selectTrack", b.text(i, "play.choose_placeholder", nil), options)
 			if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 				playLogger.Warn("Failed to create interaction menu for yt search: ", err)
+				playErr = err
 			} else {
 				var currentTrackMap = make(map[string]lavalink.AudioTrack)
 				for i := 0; i < 5; i++ {
+					setStartPosition(tracks[i], start)
 					currentTrackMap[tracks[i].Info().Identifier] = tracks[i]
 				}
 				b.TrackMap[i.Member.User.ID] = currentTrackMap
@@ -117,22 +216,133 @@ func playCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 		},
 		func() {
 			playLogger.Debug("Lavalink did not return any search results.")
-			response = SingleButtonFollowUpResponse("No matches found for your query.", "Try again or something", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "🤷")
+			response = tryAgainResponse(b, i, b.text(i, "error.no_matches", nil))
 			if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 				playLogger.Warn("Failed to create follow up message for empty query matches: ", err)
+				playErr = err
 			}
 		},
 		func(ex lavalink.FriendlyException) {
 			playLogger.Warn("Lavalink query exception: ", ex)
-			response = SingleButtonFollowUpResponse("Error while loading your queried track.", "Try again or something", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "🤷")
+			playErr = ex
+			response = tryAgainResponse(b, i, b.text(i, "error.load", nil))
 			if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 				playLogger.Warn("Failed to create follow up message for query ", err)
 			}
 		},
 	))
+	if loadErr != nil {
+		// The handler is not called if lavalink could not be reached
+		playLogger.Warn("Failed to load query: ", loadErr)
+		if _, err := s.FollowupMessageCreate(i.Interaction, true, tryAgainResponse(b, i, b.text(i, "error.load", nil))); err != nil {
+			playLogger.Warn("Failed to create follow up message for query ", err)
+		}
+		return loadErr
+	}
+	return playErr
+}
+
+// Follow up with a button to try again, unless the theme has no label for it.
+func tryAgainResponse(b *Bot, i *discordgo.InteractionCreate, content string) *discordgo.WebhookParams {
+	label := b.text(i, "play.try_again", nil)
+	if label == "" {
+		return SingleFollowUpResponse(content)
+	}
+	return SingleButtonFollowUpResponse(content, label, b.text(i, "play.try_again_url", nil), b.text(i, "play.link_emoji", nil))
+}
+
+func playMessageCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	playLogger := Logger.WithFields(logrus.Fields{
+		"cmd":       "play in voice",
+		"userID":    i.Member.User.ID,
+		"guildID":   i.GuildID,
+		"messageID": i.ApplicationCommandData().TargetID,
+	})
+	playLogger.Info("Play in voice command selected.")
+
+	var queries []string
+	if message, ok := i.ApplicationCommandData().Resolved.Messages[i.ApplicationCommandData().TargetID]; ok {
+		queries = messageQueries(message)
+	}
+	if len(queries) == 0 {
+		response := SingleInteractionResponse(b.text(i, "play.no_audio_in_message", nil),
+			discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			playLogger.Warn("Failed to create interaction response: ", err)
+		}
+		return errors.New("no audio in message")
+	}
+
+	// Defer message since it may take some time to load all queries
+	public := b.public(i)
+	deferredResponse := NewResponse(b.text(i, "deferred", nil)).Visible(public).Interaction(discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	if err := s.InteractionRespond(i.Interaction, deferredResponse); err != nil {
+		playLogger.Warn("Failed to create deferred response: ", err)
+	}
+
+	var tracks []lavalink.AudioTrack
+	for _, query := range queries {
+		if err := loadItemTimed(context.TODO(), b.Lavalink, "message", query, lavalink.NewResultHandler(
+			func(track lavalink.AudioTrack) {
+				setStartPosition(track, urlTimestamp(query))
+				tracks = append(tracks, track)
+			},
+			func(playlist lavalink.AudioPlaylist) {
+				tracks = append(tracks, playlist.Tracks()...)
+			},
+			func(results []lavalink.AudioTrack) {
+				tracks = append(tracks, results[0])
+			},
+			func() {
+				playLogger.Debug("Lavalink did not return any results for ", query)
+			},
+			func(ex lavalink.FriendlyException) {
+				playLogger.Warn("Lavalink query exception for ", query, ": ", ex)
+			},
+		)); err != nil {
+			playLogger.Warn("Failed to load ", query, ": ", err)
+		}
+	}
+
+	var response *discordgo.WebhookParams
+	var playErr error
+	if len(tracks) == 0 {
+		playErr = errors.New("no queries of the message could be loaded")
+		response = SingleFollowUpResponse(b.text(i, "play.message_load_failed", nil))
+	} else if playErr = b.Play(s, i, tracks...); playErr != nil {
+		playLogger.Warn("Error occurred while trying to play message tracks: ", playErr)
+		response = SingleFollowUpResponse(userMessage(b.style(i), playErr))
+	} else {
+		response = NewResponse(b.text(i, "play.message_added", Args{"Count": len(tracks)})).Visible(public).FollowUp()
+	}
+
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
+		playLogger.Warn("Failed to create follow up message: ", err)
+		return err
+	}
+	return playErr
 }
 
-func leaveCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+// Collects audio attachments and links of a message in order of appearance.
+func messageQueries(message *discordgo.Message) []string {
+	var queries []string
+	for _, attachment := range message.Attachments {
+		if isAudioAttachment(attachment) {
+			queries = append(queries, attachment.URL)
+		}
+	}
+	queries = append(queries, urlSearchPattern.FindAllString(message.Content, -1)...)
+	return queries
+}
+
+func isAudioAttachment(attachment *discordgo.MessageAttachment) bool {
+	if strings.HasPrefix(attachment.ContentType, "audio/") || strings.HasPrefix(attachment.ContentType, "video/") {
+		return true
+	}
+	return libraryExtensions[strings.ToLower(filepath.Ext(attachment.Filename))]
+}
+
+func leaveCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	leaveLogger := Logger.WithFields(logrus.Fields{
 		"cmd":     "leave",
 		"userID":  i.Member.User.ID,
@@ -141,35 +351,38 @@ func leaveCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot)
 	leaveLogger.Info("Leave command selected.")
 
 	var response *discordgo.InteractionResponse
+	var leaveErr error
 	// Check if bot is connected to a voice channel
-	if state, _ := s.State.VoiceState(i.GuildID, s.State.User.ID); state != nil {
-		if err := b.leave(s, i.GuildID); err != nil {
-			leaveLogger.Warn("Bot was unable to leave voice channel: ", err)
+	if state, _ := s.VoiceState(i.GuildID, s.UserID()); state != nil {
+		if leaveErr = b.leave(s, i.GuildID); leaveErr != nil {
+			leaveLogger.Warn("Bot was unable to leave voice channel: ", leaveErr)
 		}
 
-		response = SingleInteractionResponse("行ってきます、ご主人様", discordgo.InteractionResponseChannelMessageWithSource)
+		response = NewResponse(b.text(i, "farewell", nil)).Visible(b.public(i)).Interaction(discordgo.InteractionResponseChannelMessageWithSource)
 
 	} else {
-		response = SingleInteractionResponse("I'm not connected to any voice channel. Why are you trying to make me leave? :/",
+		response = SingleInteractionResponse(b.text(i, "leave.not_connected", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
 	}
 
 	if err := s.InteractionRespond(i.Interaction, response); err != nil {
 		leaveLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return leaveErr
 }
 
-func skipCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+func skipCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	// Get input string from skip command
 	data := i.ApplicationCommandData().Options[0]
 	if data == nil {
 		Logger.Warn("Expected user query but options are empty. Make sure the commands are set up properly.")
-		response := SingleInteractionResponse("An error occurred. The bot command appears to be set up incorrectly. Please try again later.",
+		response := SingleInteractionResponse(b.text(i, "setup_error", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
 		if err := s.InteractionRespond(i.Interaction, response); err != nil {
 			Logger.Warn("Failed to create interaction response: ", err)
 		}
-		return
+		return ErrInvalidOptions
 	}
 	query := fmt.Sprintf("%v", data.Name)
 
@@ -182,9 +395,11 @@ func skipCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 	skipLogger.Info("Skip command selected.")
 
 	var response *discordgo.InteractionResponse
+	var skipErr error
 	if isPlaying, err := b.IsPlaying(i.GuildID); err != nil {
 		skipLogger.Warn("An error occurred checking if a song is playing: ", err)
-		response = SingleInteractionResponse("I'm not connected. Why would you do that? 😢", discordgo.InteractionResponseChannelMessageWithSource)
+		skipErr = err
+		response = SingleInteractionResponse(userMessage(b.style(i), err), discordgo.InteractionResponseChannelMessageWithSource)
 	} else if isPlaying {
 		switch query {
 		case "all":
@@ -194,28 +409,30 @@ func skipCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 		case "single":
 			break
 		default:
-			if err := s.InteractionRespond(i.Interaction, SingleInteractionResponse("Unsupported seek option. How did you get here?",
+			if err := s.InteractionRespond(i.Interaction, SingleInteractionResponse(b.text(i, "unsupported_option", Args{"Command": "skip"}),
 				discordgo.InteractionResponseChannelMessageWithSource)); err != nil {
 				skipLogger.Warn("Failed to create interaction response: ", err)
 			}
-			return
+			return ErrInvalidOptions
 		}
-		if err := b.skip(s, i.GuildID); err != nil {
-			skipLogger.Warn("Bot was unable to skip the song: ", err)
-			response = SingleInteractionResponse("I failed to skip the song. 本当に御免なさい、ご主人様 😭", discordgo.InteractionResponseChannelMessageWithSource)
+		if skipErr = b.skip(s, i.GuildID); skipErr != nil {
+			skipLogger.Warn("Bot was unable to skip the song: ", skipErr)
+			response = SingleInteractionResponse(userMessage(b.style(i), skipErr), discordgo.InteractionResponseChannelMessageWithSource)
 		} else {
-			response = SingleInteractionResponse("Skipping song(s). 🤫", discordgo.InteractionResponseChannelMessageWithSource)
+			response = NewResponse(b.text(i, "skip.skipping", nil)).Visible(b.public(i)).Interaction(discordgo.InteractionResponseChannelMessageWithSource)
 		}
 	} else {
-		response = SingleInteractionResponse("There are no songs to skip. Why would you do that? 😢", discordgo.InteractionResponseChannelMessageWithSource)
+		response = SingleInteractionResponse(b.text(i, "skip.nothing", nil), discordgo.InteractionResponseChannelMessageWithSource)
 	}
 
 	if err := s.InteractionRespond(i.Interaction, response); err != nil {
 		skipLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return skipErr
 }
 
-func showCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+func showCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	showLogger := Logger.WithFields(logrus.Fields{
 		"cmd":     "show",
 		"userID":  i.Member.User.ID,
@@ -225,18 +442,17 @@ func showCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 
 	var response *discordgo.WebhookParams
 	// Defer message since it may take some time to retrieve the whole query
-	deferredResponse := SingleInteractionResponse("Response will soon follow.", discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	public := b.public(i)
+	deferredResponse := NewResponse(b.text(i, "deferred", nil)).Visible(public).Interaction(discordgo.InteractionResponseDeferredChannelMessageWithSource)
 	if err := s.InteractionRespond(i.Interaction, deferredResponse); err != nil {
 		showLogger.Warn("Failed to create deferred response: ", err)
 	}
 
-	if tracks, err := b.getTracks(i.GuildID); err != nil {
-		showLogger.Warn("Could not retrieve playlist: ", err)
-		response = SingleFollowUpResponse("An error occurred trying to display playlist. Please try again and make sure the bot is connected.")
+	tracks, showErr := b.getTracks(i.GuildID)
+	if showErr != nil {
+		showLogger.Warn("Could not retrieve playlist: ", showErr)
+		response = SingleFollowUpResponse(b.text(i, "show.failed", nil))
 	} else if tracks != nil {
-		var messageEmbedField []*discordgo.MessageEmbedField
-		// TODO make embeds nicer
-		// TODO prepend playing track to playlist as other embed
 		var displayNumber int
 		if len(tracks) > 5 {
 			displayNumber = 5
@@ -244,40 +460,51 @@ func showCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 			displayNumber = len(tracks)
 		}
 
+		content := NewResponse(b.text(i, "show.content", nil)).Visible(public)
 		if playing, err := b.IsPlaying(i.GuildID); err != nil {
 			showLogger.Warn("An error occurred checking if player is playing a track:", err)
 		} else if playing {
 			if playingTrack, err := b.playingTrack(i.GuildID); err != nil {
 				showLogger.Warn("An error occurred retrieving playing track:", err)
 			} else {
-				messageEmbedField = append(messageEmbedField, &discordgo.MessageEmbedField{
-					Name:   "Currently playing:",
-					Value:  playingTrack.Info().Title,
-					Inline: false,
-				})
+				position, _ := b.currentPosition(i.GuildID)
+				content.Embed(NewTrackEmbed(b.style(i), newTrackState(playingTrack)).
+					Heading(b.text(i, "embed.playing", nil)).Progress(position).Build())
 			}
-
 		}
 
-		for i := 0; i < displayNumber; i++ {
+		etas, err := b.queueETAs(i.GuildID)
+		if err != nil {
+			showLogger.Warn("An error occurred estimating the queue times:", err)
+		}
+		var messageEmbedField []*discordgo.MessageEmbedField
+		for n := 0; n < displayNumber; n++ {
+			args := Args{"Title": tracks[n].Info().Title, "Duration": trackDuration(b.style(i), newTrackState(tracks[n]))}
+			value := b.text(i, "show.entry", args)
+			if n < len(etas) && etas[n] >= 0 {
+				args["ETA"] = formatTimestamp(etas[n])
+				value = b.text(i, "show.entry_eta", args)
+			}
 			messageEmbedField = append(messageEmbedField, &discordgo.MessageEmbedField{
-				Name:   NumberEmojiMap[i+1],
-				Value:  tracks[i].Info().Title,
+				Name:   NumberEmojiMap[n+1],
+				Value:  value,
 				Inline: false,
 			})
 		}
 
-		response = SingleEmbedFollowUpResponse("Songs in the playlist are listed in the following.", "Header of the playlist:", messageEmbedField)
+		response = content.Embed(&discordgo.MessageEmbed{Title: b.text(i, "show.title", nil), Fields: messageEmbedField}).FollowUp()
 	} else {
-		response = SingleFollowUpResponse("Playlist is empty.")
+		response = NewResponse(b.text(i, "show.empty", nil)).Visible(public).FollowUp()
 	}
 
 	if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
 		showLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return showErr
 }
 
-func setCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+func setCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	setLogger := Logger.WithFields(logrus.Fields{
 		"cmd":     "set",
 		"userID":  i.Member.User.ID,
@@ -287,140 +514,448 @@ func setCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 
 	var response *discordgo.InteractionResponse
 	mode := i.ApplicationCommandData().Options[0].Name
-	if err := b.setMode(i.GuildID, mode); err != nil {
-		setLogger.Warn("Unable to set play mode: ", err)
-		response = SingleInteractionResponse("Unable to set play mode. Please try again and use one of the available modes (off, single, all).",
-			discordgo.InteractionResponseChannelMessageWithSource)
+	setErr := b.setMode(i.GuildID, mode)
+	if setErr != nil {
+		setLogger.Warn("Unable to set play mode: ", setErr)
+		response = SingleInteractionResponse(userMessage(b.style(i), setErr), discordgo.InteractionResponseChannelMessageWithSource)
 	} else {
-		response = SingleInteractionResponse(fmt.Sprintf("Set mode to: %v", mode),
-			discordgo.InteractionResponseChannelMessageWithSource)
+		response = NewResponse(b.text(i, "set.done", Args{"Mode": mode})).Visible(b.public(i)).
+			Interaction(discordgo.InteractionResponseChannelMessageWithSource)
 	}
 
 	if err := s.InteractionRespond(i.Interaction, response); err != nil {
 		setLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return setErr
 }
 
-func seekCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+func seekCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	// Get parameter name and position from seek command
 	data := i.ApplicationCommandData().Options[0]
 	if data == nil {
 		Logger.Warn("Expected user query but options are empty. Make sure the commands are set up properly.")
-		response := SingleInteractionResponse("An error occurred. The bot command appears to be set up incorrectly. Please try again later.",
+		response := SingleInteractionResponse(b.text(i, "setup_error", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
 		if err := s.InteractionRespond(i.Interaction, response); err != nil {
 			Logger.Warn("Failed to create interaction response: ", err)
 		}
-		return
+		return ErrInvalidOptions
 	}
 	query := fmt.Sprintf("%v", data.Name)
-	position := data.Options[0].IntValue()
+	value := data.Options[0].StringValue()
 
 	seekLogger := Logger.WithFields(logrus.Fields{
 		"cmd":      "seek",
 		"userID":   i.Member.User.ID,
 		"guildID":  i.GuildID,
 		"query":    query,
-		"position": position,
+		"position": value,
 	})
 	seekLogger.Info("Seek command selected.")
 
+	var position lavalink.Duration
+	var err error
+	if query == "relative" {
+		position, err = parseRelativeTimestamp(value)
+	} else {
+		position, err = parseTimestamp(value)
+	}
+
 	var response *discordgo.InteractionResponse
-	if isPlaying, err := b.IsPlaying(i.GuildID); err != nil {
+	var seekErr error
+	if err != nil {
+		seekLogger.Warn("Could not parse seek position: ", err)
+		seekErr = err
+		response = SingleInteractionResponse(b.text(i, "seek.unsupported_position", nil), discordgo.InteractionResponseChannelMessageWithSource)
+	} else if isPlaying, err := b.IsPlaying(i.GuildID); err != nil {
 		seekLogger.Warn("An error occurred checking if a song is playing: ", err)
-		response = SingleInteractionResponse("I'm not connected. Why would you do that? 😢", discordgo.InteractionResponseChannelMessageWithSource)
+		seekErr = err
+		response = SingleInteractionResponse(userMessage(b.style(i), err), discordgo.InteractionResponseChannelMessageWithSource)
 	} else if isPlaying {
-		response = seekHelper(query, b, i.GuildID, position)
+		response, seekErr = seekHelper(query, b, i, position)
 	} else {
-		response = SingleInteractionResponse("There are no songs available. Why would you do that? 😢", discordgo.InteractionResponseChannelMessageWithSource)
+		response = SingleInteractionResponse(b.text(i, "seek.nothing", nil), discordgo.InteractionResponseChannelMessageWithSource)
 	}
 
 	if err := s.InteractionRespond(i.Interaction, response); err != nil {
 		seekLogger.Warn("Failed to create interaction response to seek command: ", err)
+		return err
+	}
+	return seekErr
+}
+
+// Seeks the playing track and returns the response, along with the error if seeking failed.
+func seekHelper(query string, b *Bot, i *discordgo.InteractionCreate, position lavalink.Duration) (*discordgo.InteractionResponse, error) {
+	switch query {
+	case "absolute":
+		return seekAbsolute(b, i, position)
+	case "relative":
+		return seekRelative(b, i, position)
+	default:
+		return SingleInteractionResponse(Messages.Text(b.style(i), "unsupported_option", Args{"Command": "seek"}),
+			discordgo.InteractionResponseChannelMessageWithSource), ErrInvalidOptions
 	}
 }
 
-func seekHelper(query string, b *Bot, guildID string, position int64) *discordgo.InteractionResponse {
-	// Check if player is playing a track and retrieve it
-	isPlaying, err := b.IsPlaying(guildID)
+func seekAbsolute(b *Bot, i *discordgo.InteractionCreate, position lavalink.Duration) (*discordgo.InteractionResponse, error) {
+	style := b.style(i)
+	seekPosition, err := b.seekPlaying(i.GuildID, position, false)
 	if err != nil {
-		Logger.Warn("Error trying to check if player is playing a track: ", err)
-		return SingleInteractionResponse("Unable to check if a track is playing.", discordgo.InteractionResponseChannelMessageWithSource)
-	} else if !isPlaying {
+		return seekErrorResponse(style, err), err
+	}
+
+	return NewResponse(Messages.Text(style, "seek.absolute", Args{"Position": formatTimestamp(seekPosition)})).Visible(b.public(i)).
+		Interaction(discordgo.InteractionResponseChannelMessageWithSource), nil
+}
+
+func seekRelative(b *Bot, i *discordgo.InteractionCreate, position lavalink.Duration) (*discordgo.InteractionResponse, error) {
+	style := b.style(i)
+	seekPosition, err := b.seekPlaying(i.GuildID, position, true)
+	if errors.Is(err, ErrNoPlayer) {
+		Logger.Warn("Bot was unable to retrieve the current position of the player. Bot appears to not be connected.")
+		return SingleInteractionResponse(Messages.Text(style, "seek.position_failed", nil),
+			discordgo.InteractionResponseChannelMessageWithSource), err
+	} else if err != nil {
+		return seekErrorResponse(style, err), err
+	}
+
+	return NewResponse(Messages.Text(style, "seek.relative", Args{"Position": formatTimestamp(seekPosition)})).Visible(b.public(i)).
+		Interaction(discordgo.InteractionResponseChannelMessageWithSource), nil
+}
+
+func seekErrorResponse(style Style, err error) *discordgo.InteractionResponse {
+	if errors.Is(err, ErrNotPlaying) {
 		Logger.Warn("Seek command called when no playing track available.")
-		return SingleInteractionResponse("No track is playing.", discordgo.InteractionResponseChannelMessageWithSource)
+		return SingleInteractionResponse(Messages.Text(style, "seek.not_playing", nil), discordgo.InteractionResponseChannelMessageWithSource)
 	}
+	Logger.Warn("Bot was unable to seek position: ", err)
+	return SingleInteractionResponse(userMessage(style, err), discordgo.InteractionResponseChannelMessageWithSource)
+}
 
-	playingTrack, err := b.playingTrack(guildID)
-	if err != nil || playingTrack == nil {
-		Logger.Warn("Error trying to retrieve playing track ", playingTrack, " : ", err)
-		return SingleInteractionResponse("No track is playing.", discordgo.InteractionResponseChannelMessageWithSource)
+func loopCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	// Get section boundaries from loop command
+	data := i.ApplicationCommandData().Options[0]
+	if data == nil || len(data.Options) < 2 {
+		Logger.Warn("Expected section boundaries but options are empty. Make sure the commands are set up properly.")
+		response := SingleInteractionResponse(b.text(i, "setup_error", nil),
+			discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			Logger.Warn("Failed to create interaction response: ", err)
+		}
+		return ErrInvalidOptions
 	}
 
-	switch query {
-	case "absolute":
-		return seekAbsolute(b, guildID, position, playingTrack)
-	case "relative":
-		return seekRelative(b, guildID, position, playingTrack)
-	default:
-		return SingleInteractionResponse("Unsupported seek option. How did you get here?",
+	var startValue, endValue string
+	for _, option := range data.Options {
+		switch option.Name {
+		case "start":
+			startValue = option.StringValue()
+		case "end":
+			endValue = option.StringValue()
+		}
+	}
+
+	loopLogger := Logger.WithFields(logrus.Fields{
+		"cmd":     "loop",
+		"userID":  i.Member.User.ID,
+		"guildID": i.GuildID,
+		"query":   data.Name,
+		"start":   startValue,
+		"end":     endValue,
+	})
+	loopLogger.Info("Loop command selected.")
+
+	var response *discordgo.InteractionResponse
+	var loopErr error
+	start, startErr := parseTimestamp(startValue)
+	end, endErr := parseTimestamp(endValue)
+	if startErr != nil || endErr != nil {
+		loopLogger.Warn("Could not parse section boundaries: ", startErr, endErr)
+		loopErr = ErrInvalidOptions
+		response = SingleInteractionResponse(b.text(i, "loop.unsupported_section", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
+	} else if loopErr = b.loopSection(i.GuildID, start, end); loopErr != nil {
+		loopLogger.Warn("Unable to loop section: ", loopErr)
+		response = SingleInteractionResponse(userMessage(b.style(i), loopErr), discordgo.InteractionResponseChannelMessageWithSource)
+	} else {
+		response = NewResponse(b.text(i, "loop.done", Args{"Start": formatTimestamp(start), "End": formatTimestamp(end)})).Visible(b.public(i)).
+			Interaction(discordgo.InteractionResponseChannelMessageWithSource)
+	}
+
+	if err := s.InteractionRespond(i.Interaction, response); err != nil {
+		loopLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return loopErr
 }
 
-func seekAbsolute(b *Bot, guildID string, position int64, playingTrack lavalink.AudioTrack) *discordgo.InteractionResponse {
-	songDuration := playingTrack.Info().Length
+func autoplayCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	autoplayLogger := Logger.WithFields(logrus.Fields{
+		"cmd":     "autoplay",
+		"userID":  i.Member.User.ID,
+		"guildID": i.GuildID,
+	})
+	autoplayLogger.Info("Autoplay command selected.")
+
+	var response *discordgo.InteractionResponse
+	mode := i.ApplicationCommandData().Options[0].Name
+	autoplayErr := b.setAutoplay(i.GuildID, mode == "on")
+	if autoplayErr != nil {
+		autoplayLogger.Warn("Unable to set autoplay: ", autoplayErr)
+		response = SingleInteractionResponse(userMessage(b.style(i), autoplayErr), discordgo.InteractionResponseChannelMessageWithSource)
+	} else {
+		response = NewResponse(b.text(i, "autoplay.done", Args{"Mode": mode})).Visible(b.public(i)).
+			Interaction(discordgo.InteractionResponseChannelMessageWithSource)
+	}
 
-	// Skip to the end if position is greater than duration of song
-	// Skip to the start if the position is negative
-	if position > songDuration.Seconds() {
-		position = songDuration.Seconds()
-	} else if position < 0 {
-		position = 0
+	if err := s.InteractionRespond(i.Interaction, response); err != nil {
+		autoplayLogger.Warn("Failed to create interaction response: ", err)
+		return err
 	}
+	return autoplayErr
+}
 
-	if err := b.seek(guildID, lavalink.Duration(position*1000)); err != nil {
-		Logger.Warn("Bot was unable to seek position: ", err)
-		return SingleInteractionResponse(fmt.Sprintf("I failed to seek absolute position %d in the song. 本当に御免なさい、ご主人様 😭", position),
+func libraryCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	// Get subcommand and query from library command
+	data := i.ApplicationCommandData().Options[0]
+	if data == nil {
+		Logger.Warn("Expected library subcommand but options are empty. Make sure the commands are set up properly.")
+		response := SingleInteractionResponse(b.text(i, "setup_error", nil),
 			discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			Logger.Warn("Failed to create interaction response: ", err)
+		}
+		return ErrInvalidOptions
+	}
+	var query string
+	if len(data.Options) > 0 {
+		query = data.Options[0].StringValue()
+	}
+
+	libraryLogger := Logger.WithFields(logrus.Fields{
+		"cmd":     "library",
+		"userID":  i.Member.User.ID,
+		"guildID": i.GuildID,
+		"sub":     data.Name,
+		"query":   query,
+	})
+	libraryLogger.Info("Library command selected.")
+
+	library := b.library()
+	if library == nil {
+		response := SingleInteractionResponse(b.text(i, "library.disabled", nil), discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			libraryLogger.Warn("Failed to create interaction response: ", err)
+		}
+		return errors.New("library is disabled")
+	}
+
+	if data.Name == "rescan" && !b.canRescan(i) {
+		response := SingleInteractionResponse(b.text(i, "library.rescan_denied", nil), discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			libraryLogger.Warn("Failed to create interaction response: ", err)
+		}
+		return ErrPermissionDenied
+	}
+
+	// Defer message since scanning and joining may take some time
+	public := b.public(i)
+	deferredResponse := NewResponse(b.text(i, "deferred", nil)).Visible(public).Interaction(discordgo.InteractionResponseDeferredChannelMessageWithSource)
+	if err := s.InteractionRespond(i.Interaction, deferredResponse); err != nil {
+		libraryLogger.Warn("Failed to create deferred response: ", err)
+	}
+
+	var response *discordgo.WebhookParams
+	var libraryErr error
+	switch data.Name {
+	case "search":
+		tracks := library.Search(query, 5)
+		if len(tracks) == 0 {
+			libraryErr = ErrNoMatches
+			response = SingleFollowUpResponse(b.text(i, "library.no_match", nil))
+			break
+		}
+
+		var options []discordgo.SelectMenuOption
+		var currentTrackMap = make(map[string]lavalink.AudioTrack)
+		for n, track := range tracks {
+			value := fmt.Sprintf("library-%d", n)
+			description := strings.TrimSpace(track.Artist + " " + track.Album)
+			if description == "" {
+				description = b.text(i, "library.local", nil)
+			}
+			options = append(options, discordgo.SelectMenuOption{
+				Label:       truncate(track.Title, 100),
+				Description: truncate(description, 100),
+				Value:       value,
+				Emoji: discordgo.ComponentEmoji{
+					Name: NumberEmojiMap[n+1],
+				},
+			})
+			currentTrackMap[value] = track.Track.Clone()
+		}
+		b.TrackMap[i.Member.User.ID] = currentTrackMap
+		response = SingleSelectMenuFollowUpResponse(b.text(i, "play.choose", nil), "selectTrack", b.text(i, "library.choose_placeholder", nil), options)
+
+	case "play":
+		var track LibraryTrack
+		if track, libraryErr = library.Find(query); libraryErr != nil {
+			libraryLogger.Debug("No library match: ", libraryErr)
+			response = SingleFollowUpResponse(b.text(i, "library.no_match", nil))
+		} else if libraryErr = b.Play(s, i, track.Track.Clone()); libraryErr != nil {
+			libraryLogger.Warn("Error occurred while trying to play library track: ", libraryErr)
+			response = SingleFollowUpResponse(userMessage(b.style(i), libraryErr))
+		} else {
+			response = NewResponse(b.text(i, "library.added", Args{"Title": track.Title})).Visible(public).FollowUp()
+		}
+
+	case "rescan":
+		if count, err := library.Scan(context.TODO(), b.Lavalink); err != nil {
+			libraryLogger.Warn("Failed to rescan library: ", err)
+			libraryErr = err
+			response = SingleFollowUpResponse(b.text(i, "library.scan_failed", nil))
+		} else {
+			response = NewResponse(b.text(i, "library.scanned", Args{"Count": count})).Visible(public).FollowUp()
+		}
+
+	default:
+		libraryErr = ErrInvalidOptions
+		response = SingleFollowUpResponse(b.text(i, "unsupported_option", Args{"Command": "library"}))
 	}
 
-	return SingleInteractionResponse(fmt.Sprintf("Seeking absolute position %d in song. 🤫", position),
-		discordgo.InteractionResponseChannelMessageWithSource)
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, response); err != nil {
+		libraryLogger.Warn("Failed to create follow up message: ", err)
+		return err
+	}
+	return libraryErr
 }
 
-func seekRelative(b *Bot, guildID string, position int64, playingTrack lavalink.AudioTrack) *discordgo.InteractionResponse {
-	songPosition, err := b.currentPosition(guildID)
+// Rescanning loads every library file through lavalink, so it needs the DJ role,
+// or the manage server permission in guilds without a DJ role.
+func (b *Bot) canRescan(i *discordgo.InteractionCreate) bool {
+	if b.Settings.Get(i.GuildID).DJRole == "" {
+		return i.Member != nil && canManageServer(i.Member)
+	}
+	return b.isDJ(i)
+}
 
-	if err != nil {
-		Logger.Warn("Bot was unable to retrieve the current position of the player: ", err)
-		return SingleInteractionResponse("I failed to seek relative position in the song. 本当に御免なさい、ご主人様 😭",
-			discordgo.InteractionResponseChannelMessageWithSource)
-	} else if songPosition == -1 {
-		Logger.Warn("Bot was unable to retrieve the current position of the player. Bot appears to not be connected.")
-		return SingleInteractionResponse("I failed to seek relative position in the song. 本当に御免なさい、ご主人様 😭",
-			discordgo.InteractionResponseChannelMessageWithSource)
+func truncate(text string, length int) string {
+	if runes := []rune(text); len(runes) > length {
+		return string(runes[:length-1]) + "…"
+	}
+	return text
+}
+
+func settingsCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	data := i.ApplicationCommandData().Options[0]
+	var key, value string
+	for _, option := range data.Options {
+		switch option.Name {
+		case "key":
+			key = option.StringValue()
+		case "value":
+			value = option.StringValue()
+		}
+	}
+
+	settingsLogger := Logger.WithFields(logrus.Fields{
+		"cmd":     "settings",
+		"userID":  i.Member.User.ID,
+		"guildID": i.GuildID,
+		"sub":     data.Name,
+		"key":     key,
+		"value":   value,
+	})
+	settingsLogger.Info("Settings command selected.")
+
+	var response *discordgo.InteractionResponse
+	var settingsErr error
+	if !canManageServer(i.Member) {
+		settingsErr = ErrPermissionDenied
+		response = SingleInteractionResponse(b.text(i, "settings.no_permission", nil), discordgo.InteractionResponseChannelMessageWithSource)
+	} else {
+		switch data.Name {
+		case "view":
+			response = SingleEmbedInteractionResponse(b.text(i, "settings.view", nil), b.text(i, "settings.title", nil), settingsEmbedFields(b.Settings.Get(i.GuildID)))
+		case "set":
+			if settingsErr = b.Settings.Set(i.GuildID, key, value); settingsErr != nil {
+				settingsLogger.Warn("Unable to change setting: ", settingsErr)
+				response = SingleInteractionResponse(b.text(i, "settings.set_failed", Args{"Key": key, "Error": settingsErr}), discordgo.InteractionResponseChannelMessageWithSource)
+			} else {
+				response = SingleInteractionResponse(b.text(i, "settings.set", Args{"Key": key, "Value": value}), discordgo.InteractionResponseChannelMessageWithSource)
+			}
+		case "reset":
+			if settingsErr = b.Settings.Reset(i.GuildID, key); settingsErr != nil {
+				settingsLogger.Warn("Unable to reset settings: ", settingsErr)
+				response = SingleInteractionResponse(b.text(i, "settings.reset_failed", nil), discordgo.InteractionResponseChannelMessageWithSource)
+			} else if key == "" {
+				response = SingleInteractionResponse(b.text(i, "settings.reset_all", nil), discordgo.InteractionResponseChannelMessageWithSource)
+			} else {
+				response = SingleInteractionResponse(b.text(i, "settings.reset", Args{"Key": key}), discordgo.InteractionResponseChannelMessageWithSource)
+			}
+		default:
+			settingsErr = ErrInvalidOptions
+			response = SingleInteractionResponse(b.text(i, "unsupported_option", Args{"Command": "settings"}), discordgo.InteractionResponseChannelMessageWithSource)
+		}
 	}
 
-	seekPosition := songPosition.Seconds() + position
+	if err := s.InteractionRespond(i.Interaction, response); err != nil {
+		settingsLogger.Warn("Failed to create interaction response: ", err)
+		return err
+	}
+	return settingsErr
+}
 
-	if seekPosition < 0 {
-		seekPosition = 0
-	} else if seekPosition > playingTrack.Info().Length.Seconds() {
-		seekPosition = playingTrack.Info().Length.Seconds()
+func settingsEmbedFields(settings GuildSettings) []*discordgo.MessageEmbedField {
+	var fields []*discordgo.MessageEmbedField
+	values := reflect.ValueOf(settings)
+	for n := 0; n < values.NumField(); n++ {
+		field := values.Type().Field(n)
+		value := fmt.Sprintf("%v", values.Field(n).Interface())
+		if value == "" {
+			value = "-"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   field.Tag.Get("json"),
+			Value:  value + "\n" + field.Tag.Get("doc"),
+			Inline: false,
+		})
 	}
+	return fields
+}
 
-	if err = b.seek(guildID, lavalink.Duration(seekPosition*1000)); err != nil {
-		Logger.Warn("Bot was unable to seek position: ", err)
-		return SingleInteractionResponse(fmt.Sprintf("I failed to seek relative position %d in the song. 本当に御免なさい、ご主人様 😭", seekPosition),
-			discordgo.InteractionResponseChannelMessageWithSource)
+func dashboardCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
+	dashboardLogger := Logger.WithFields(logrus.Fields{
+		"cmd":     "dashboard",
+		"userID":  i.Member.User.ID,
+		"guildID": i.GuildID,
+	})
+	dashboardLogger.Info("Dashboard command selected.")
+
+	var response *discordgo.InteractionResponse
+	var dashboardErr error
+	conf := b.config()
+	if conf.DashboardURL == "none" || conf.HTTPAddress == "none" {
+		dashboardErr = errors.New("dashboard is disabled")
+		response = SingleInteractionResponse(b.text(i, "dashboard.disabled", nil), discordgo.InteractionResponseChannelMessageWithSource)
+	} else if link, err := b.Dashboard.Link(conf.DashboardURL, i.GuildID, i.Member.User.ID, b.isDJ(i)); err != nil {
+		dashboardLogger.Warn("Failed to create dashboard link: ", err)
+		dashboardErr = err
+		response = SingleInteractionResponse(b.text(i, "dashboard.link_failed", nil), discordgo.InteractionResponseChannelMessageWithSource)
+	} else {
+		content := b.text(i, "dashboard.link", Args{"Minutes": int(dashboardTokenTTL.Minutes())})
+		response = SingleButtonInteractionResponse(content, b.text(i, "dashboard.open", nil), link, b.text(i, "dashboard.open_emoji", nil), discordgo.InteractionResponseChannelMessageWithSource)
 	}
 
-	return SingleInteractionResponse(fmt.Sprintf("Seeking relative position %d in song. 🤫", seekPosition),
-		discordgo.InteractionResponseChannelMessageWithSource)
+	if err := s.InteractionRespond(i.Interaction, response); err != nil {
+		dashboardLogger.Warn("Failed to create interaction response: ", err)
+		return err
+	}
+	return dashboardErr
 }
 
-func exitCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+func exitCommand(s Session, i *discordgo.InteractionCreate, b *Bot) error {
 	exitLogger := Logger.WithFields(logrus.Fields{
 		"cmd":     "exit",
 		"userID":  i.Member.User.ID,
@@ -428,14 +963,23 @@ func exitCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 	})
 	exitLogger.Info("Exit command selected.")
 
+	// Exiting stops the bot for every guild, so it needs at least the permission to change settings
+	if !canManageServer(i.Member) {
+		response := SingleInteractionResponse(b.text(i, "exit.no_permission", nil), discordgo.InteractionResponseChannelMessageWithSource)
+		if err := s.InteractionRespond(i.Interaction, response); err != nil {
+			exitLogger.Warn("Failed to create interaction response: ", err)
+		}
+		return ErrPermissionDenied
+	}
+
 	var response *discordgo.InteractionResponse
 	// Leave if bot is connected to voice channel
-	if state, _ := s.State.VoiceState(i.GuildID, s.State.User.ID); state != nil {
+	if state, _ := s.VoiceState(i.GuildID, s.UserID()); state != nil {
 		if err := b.leave(s, i.GuildID); err != nil {
 			exitLogger.Warn("Bot was unable to leave voice channel: ", err)
 		}
 	}
-	response = SingleInteractionResponse("行ってきます、ご主人様", discordgo.InteractionResponseChannelMessageWithSource)
+	response = NewResponse(b.text(i, "farewell", nil)).Visible(b.public(i)).Interaction(discordgo.InteractionResponseChannelMessageWithSource)
 
 	if err := s.InteractionRespond(i.Interaction, response); err != nil {
 		exitLogger.Warn("Failed to create interaction response: ", err)
@@ -443,4 +987,5 @@ func exitCommand(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
 
 	// Exit program
 	os.Exit(0)
+	return nil
 }
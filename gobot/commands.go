@@ -0,0 +1,416 @@
+package gobot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Definitions of all application commands handled by the bot.
+func ApplicationCommands() []*discordgo.ApplicationCommand {
+	// play command
+	playCmd := discordgo.ApplicationCommand{
+		Name:        "play",
+		Description: "Play a query song.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "Song query that should be played.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "attachment",
+				Description: "Audio file that should be played.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "start",
+				Description: "Start position of the song (e.g. 90, 1:23 or 1h2m3s).",
+				Required:    false,
+			},
+		},
+	}
+
+	// leave command
+	leaveCmd := discordgo.ApplicationCommand{
+		Name:        "leave",
+		Description: "Leave the current voice channel.",
+	}
+
+	// skip command
+	skipCmd := discordgo.ApplicationCommand{
+		Name:        "skip",
+		Description: "Skip one or all songs.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "single",
+				Description: "Skip the currently playing song.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "all",
+				Description: "Skip all songs in the playlist.",
+			},
+		},
+	}
+
+	// playlist command
+	playlistCmd := discordgo.ApplicationCommand{
+		Name:        "show",
+		Description: "Display the current playlist.",
+	}
+
+	// play mode command
+	setCmd := discordgo.ApplicationCommand{
+		Name:        "set",
+		Description: "Set the play mode.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "off",
+				Description: "No play mode.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "single",
+				Description: "Single repeat mode.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "all",
+				Description: "All repeat mode.",
+			},
+		},
+	}
+
+	// seek command
+	seekCmd := discordgo.ApplicationCommand{
+		Name:        "seek",
+		Description: "Jump to a position in a song (e.g. 90, 1:23 or 1h2m3s).",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "relative",
+				Description: "Jump to relative position from current position (e.g. -30, +1:00 or 1m).",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "position",
+						Description: "Relative position (negative values jump back).",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "absolute",
+				Description: "Jump to absolute position (e.g. 90, 1:23 or 1h2m3s).",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "position",
+						Description: "Absolute position.",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}
+
+	// loop command
+	loopCmd := discordgo.ApplicationCommand{
+		Name:        "loop",
+		Description: "Loop a part of the playing song.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "section",
+				Description: "Repeat the section between start and end. Use /set off to stop.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "start",
+						Description: "Start of the section (e.g. 90, 1:23 or 1h2m3s).",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "end",
+						Description: "End of the section (e.g. 90, 1:23 or 1h2m3s).",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}
+
+	// autoplay command
+	autoplayCmd := discordgo.ApplicationCommand{
+		Name:        "autoplay",
+		Description: "Play related songs once the playlist runs out.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "on",
+				Description: "Enable autoplay.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "off",
+				Description: "Disable autoplay.",
+			},
+		},
+	}
+
+	// library command
+	libraryCmd := discordgo.ApplicationCommand{
+		Name:        "library",
+		Description: "Search and play songs from the local music library.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "search",
+				Description: "Search the library by title, artist or album.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "query",
+						Description: "Title, artist or album to search for.",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Play the best matching song from the library.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "query",
+						Description: "Title, artist or album to play.",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "rescan",
+				Description: "Rescan the library directory for new songs.",
+			},
+		},
+	}
+
+	// message context menu command
+	playMessageCmd := discordgo.ApplicationCommand{
+		Name: "Play in voice",
+		Type: discordgo.MessageApplicationCommand,
+	}
+
+	// settings command
+	var settingChoices []*discordgo.ApplicationCommandOptionChoice
+	for key := range GuildSettingKeys() {
+		settingChoices = append(settingChoices, &discordgo.ApplicationCommandOptionChoice{Name: key, Value: key})
+	}
+	sort.Slice(settingChoices, func(i, j int) bool {
+		return settingChoices[i].Name < settingChoices[j].Name
+	})
+	settingsCmd := discordgo.ApplicationCommand{
+		Name:        "settings",
+		Description: "View or change the settings of this server (requires Manage Server).",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show the current settings.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Change a setting.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "Setting to change.",
+						Required:    true,
+						Choices:     settingChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "value",
+						Description: "New value of the setting.",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "Reset one or all settings to their defaults.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "Setting to reset. Resets all settings if empty.",
+						Required:    false,
+						Choices:     settingChoices,
+					},
+				},
+			},
+		},
+	}
+
+	// dashboard command
+	dashboardCmd := discordgo.ApplicationCommand{
+		Name:        "dashboard",
+		Description: "Get a link to the web dashboard of the queue.",
+	}
+
+	// exit command
+	exitCmd := discordgo.ApplicationCommand{
+		Name:        "exit",
+		Description: "Bot program termination.",
+	}
+	// TODO set permission for command
+
+	commands := []*discordgo.ApplicationCommand{&playCmd, &leaveCmd, &skipCmd, &playlistCmd, &setCmd, &seekCmd, &loopCmd, &autoplayCmd, &libraryCmd, &settingsCmd, &dashboardCmd, &playMessageCmd, &exitCmd}
+	localizeCommands(commands)
+	return commands
+}
+
+// Compared fields of an application command. Discord fills in IDs and versions, so whole commands can't be compared.
+type commandDefinition struct {
+	Type                     discordgo.ApplicationCommandType      `json:"type"`
+	Name                     string                                `json:"name"`
+	NameLocalizations        map[discordgo.Locale]string           `json:"name_localizations"`
+	Description              string                                `json:"description"`
+	DescriptionLocalizations map[discordgo.Locale]string           `json:"description_localizations"`
+	Options                  []*discordgo.ApplicationCommandOption `json:"options"`
+}
+
+func newCommandDefinition(command *discordgo.ApplicationCommand) commandDefinition {
+	definition := commandDefinition{
+		Type:        command.Type,
+		Name:        command.Name,
+		Description: command.Description,
+		Options:     normalizeOptions(command.Options),
+	}
+	if definition.Type == 0 {
+		definition.Type = discordgo.ChatApplicationCommand
+	}
+	if command.NameLocalizations != nil && len(*command.NameLocalizations) > 0 {
+		definition.NameLocalizations = *command.NameLocalizations
+	}
+	if command.DescriptionLocalizations != nil && len(*command.DescriptionLocalizations) > 0 {
+		definition.DescriptionLocalizations = *command.DescriptionLocalizations
+	}
+	return definition
+}
+
+// Copies the options with empty lists as nil, since discord leaves them out of registered commands.
+func normalizeOptions(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+	normalized := make([]*discordgo.ApplicationCommandOption, len(options))
+	for i, option := range options {
+		copied := *option
+		copied.Options = normalizeOptions(option.Options)
+		if len(copied.ChannelTypes) == 0 {
+			copied.ChannelTypes = nil
+		}
+		if len(copied.Choices) == 0 {
+			copied.Choices = nil
+		}
+		normalized[i] = &copied
+	}
+	return normalized
+}
+
+func sameCommand(a *discordgo.ApplicationCommand, b *discordgo.ApplicationCommand) bool {
+	left, errLeft := json.Marshal(newCommandDefinition(a))
+	right, errRight := json.Marshal(newCommandDefinition(b))
+	return errLeft == nil && errRight == nil && bytes.Equal(left, right)
+}
+
+// Registered commands including their localizations, which discord leaves out by default.
+func registeredCommands(s *discordgo.Session, appID string, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	endpoint := discordgo.EndpointApplicationGlobalCommands(appID)
+	if guildID != "" {
+		endpoint = discordgo.EndpointApplicationGuildCommands(appID, guildID)
+	}
+	body, err := s.RequestWithBucketID(http.MethodGet, endpoint+"?with_localizations=true", nil, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []*discordgo.ApplicationCommand
+	err = json.Unmarshal(body, &commands)
+	return commands, err
+}
+
+// Creates, updates and deletes commands so the registered commands match the desired ones.
+// Unchanged commands are left alone and failures are collected instead of aborting the sync.
+func syncCommands(s *discordgo.Session, appID string, guildID string, desired []*discordgo.ApplicationCommand) error {
+	registered, err := registeredCommands(s, appID, guildID)
+	if err != nil {
+		return fmt.Errorf("could not retrieve registered commands: %w", err)
+	}
+
+	type commandKey struct {
+		name        string
+		commandType discordgo.ApplicationCommandType
+	}
+	keyOf := func(command *discordgo.ApplicationCommand) commandKey {
+		return commandKey{name: command.Name, commandType: newCommandDefinition(command).Type}
+	}
+
+	existing := map[commandKey]*discordgo.ApplicationCommand{}
+	for _, command := range registered {
+		existing[keyOf(command)] = command
+	}
+
+	var problems []string
+	for _, command := range desired {
+		current, ok := existing[keyOf(command)]
+		delete(existing, keyOf(command))
+
+		switch {
+		case !ok:
+			Logger.Info("Creating command ", command.Name)
+			if _, err := s.ApplicationCommandCreate(appID, guildID, command); err != nil {
+				problems = append(problems, fmt.Sprintf("creating %s: %v", command.Name, err))
+			}
+		case !sameCommand(current, command):
+			Logger.Info("Updating command ", command.Name)
+			if _, err := s.ApplicationCommandEdit(appID, guildID, current.ID, command); err != nil {
+				problems = append(problems, fmt.Sprintf("updating %s: %v", command.Name, err))
+			}
+		default:
+			Logger.Debug("Command ", command.Name, " is up to date")
+		}
+	}
+
+	for _, command := range existing {
+		Logger.Info("Deleting command ", command.Name)
+		if err := s.ApplicationCommandDelete(appID, guildID, command.ID); err != nil {
+			problems = append(problems, fmt.Sprintf("deleting %s: %v", command.Name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("failed to sync commands: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
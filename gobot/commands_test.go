@@ -0,0 +1,251 @@
+package gobot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Marshals the value like discord, which leaves out empty and false fields.
+func discordJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	data, err = json.Marshal(omitEmpty(generic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func omitEmpty(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			switch field := omitEmpty(field).(type) {
+			case nil, bool:
+				if field == nil || field == false {
+					delete(value, key)
+					continue
+				}
+				value[key] = field
+			case []interface{}:
+				if len(field) == 0 {
+					delete(value, key)
+					continue
+				}
+				value[key] = field
+			case map[string]interface{}:
+				if len(field) == 0 {
+					delete(value, key)
+					continue
+				}
+				value[key] = field
+			default:
+				value[key] = field
+			}
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = omitEmpty(value[i])
+		}
+	}
+	return v
+}
+
+// Round trips the command through JSON the way discord returns registered commands.
+func registeredCopy(t *testing.T, command *discordgo.ApplicationCommand, id string) *discordgo.ApplicationCommand {
+	t.Helper()
+	var registered discordgo.ApplicationCommand
+	if err := json.Unmarshal(discordJSON(t, command), &registered); err != nil {
+		t.Fatal(err)
+	}
+	registered.ID = id
+	registered.ApplicationID = "1"
+	registered.Version = "2"
+	return &registered
+}
+
+func TestSameCommand(t *testing.T) {
+	empty := map[discordgo.Locale]string{}
+	localized := map[discordgo.Locale]string{discordgo.Japanese: "再生"}
+	minimum := 1.0
+
+	base := func() *discordgo.ApplicationCommand {
+		return &discordgo.ApplicationCommand{
+			Name:        "play",
+			Description: "Play a song.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "url",
+					Description: "Play from an url.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{Type: discordgo.ApplicationCommandOptionString, Name: "url", Description: "URL of the song.", Required: true},
+						{Type: discordgo.ApplicationCommandOptionInteger, Name: "count", Description: "Times to play.", MinValue: &minimum, MaxValue: 10},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "random",
+					Description: "Play a random song.",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(command *discordgo.ApplicationCommand)
+		same   bool
+	}{
+		{name: "server filled fields", change: func(c *discordgo.ApplicationCommand) { c.ID, c.ApplicationID, c.Version = "1", "2", "3" }, same: true},
+		{name: "explicit chat type", change: func(c *discordgo.ApplicationCommand) { c.Type = discordgo.ChatApplicationCommand }, same: true},
+		{name: "empty localizations", change: func(c *discordgo.ApplicationCommand) {
+			c.NameLocalizations, c.DescriptionLocalizations = &empty, &empty
+		}, same: true},
+		{name: "empty subcommand options", change: func(c *discordgo.ApplicationCommand) { c.Options[1].Options = []*discordgo.ApplicationCommandOption{} }, same: true},
+		{name: "empty channel types", change: func(c *discordgo.ApplicationCommand) {
+			c.Options[0].Options[0].ChannelTypes = []discordgo.ChannelType{}
+		}, same: true},
+		{name: "empty choices", change: func(c *discordgo.ApplicationCommand) {
+			c.Options[0].Options[0].Choices = []*discordgo.ApplicationCommandOptionChoice{}
+		}, same: true},
+		{name: "empty options", change: func(c *discordgo.ApplicationCommand) { c.Options = []*discordgo.ApplicationCommandOption{} }, same: false},
+		{name: "changed description", change: func(c *discordgo.ApplicationCommand) { c.Description = "Play a track." }, same: false},
+		{name: "changed localization", change: func(c *discordgo.ApplicationCommand) { c.NameLocalizations = &localized }, same: false},
+		{name: "changed option localization", change: func(c *discordgo.ApplicationCommand) { c.Options[0].NameLocalizations = localized }, same: false},
+		{name: "changed required option", change: func(c *discordgo.ApplicationCommand) { c.Options[0].Options[0].Required = false }, same: false},
+		{name: "changed maximum", change: func(c *discordgo.ApplicationCommand) { c.Options[0].Options[1].MaxValue = 5 }, same: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := base()
+			test.change(changed)
+			if same := sameCommand(base(), changed); same != test.same {
+				t.Errorf("expected sameCommand to be %v, got %v", test.same, same)
+			}
+		})
+	}
+
+	for _, command := range ApplicationCommands() {
+		t.Run("registered "+command.Name, func(t *testing.T) {
+			if !sameCommand(registeredCopy(t, command, "1"), command) {
+				t.Errorf("%s differs from its registered copy:\n%s", command.Name, discordJSON(t, command))
+			}
+		})
+	}
+}
+
+// Keeps registered commands in memory like the discord API and counts the requests that change them.
+type fakeCommandAPI struct {
+	mu       sync.Mutex
+	commands map[string]*discordgo.ApplicationCommand // maps IDs to registered commands
+	nextID   int
+	writes   map[string]int // maps HTTP methods to their number of requests
+	t        *testing.T
+}
+
+func (api *fakeCommandAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		var commands []*discordgo.ApplicationCommand
+		for _, command := range api.commands {
+			commands = append(commands, command)
+		}
+		_, _ = w.Write(discordJSON(api.t, commands))
+		return
+	}
+
+	api.writes[r.Method]++
+	id := path.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodDelete:
+		delete(api.commands, id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+		api.nextID++
+		id = strconv.Itoa(api.nextID)
+	}
+
+	var command discordgo.ApplicationCommand
+	if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	api.commands[id] = registeredCopy(api.t, &command, id)
+	_, _ = w.Write(discordJSON(api.t, api.commands[id]))
+}
+
+// Sends the requests of the session to the server instead of discord.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.URL.Scheme = rt.target.Scheme
+	r.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestSyncCommands(t *testing.T) {
+	api := &fakeCommandAPI{commands: map[string]*discordgo.ApplicationCommand{}, writes: map[string]int{}, t: t}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := discordgo.New("Bot token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client = &http.Client{Transport: redirectTransport{target: target}}
+
+	syncWith := func(desired []*discordgo.ApplicationCommand) map[string]int {
+		t.Helper()
+		api.writes = map[string]int{}
+		if err := syncCommands(s, "1", testGuildID, desired); err != nil {
+			t.Fatalf("syncing commands: %v", err)
+		}
+		return api.writes
+	}
+
+	commands := ApplicationCommands()
+	if writes := syncWith(commands); writes[http.MethodPost] != len(commands) || len(writes) != 1 {
+		t.Errorf("expected %d created commands, got %v", len(commands), writes)
+	}
+	if writes := syncWith(commands); len(writes) != 0 {
+		t.Errorf("expected unchanged commands to be left alone, got %v", writes)
+	}
+
+	changed := ApplicationCommands()
+	changed[0].Description = "Changed description."
+	if writes := syncWith(changed); writes[http.MethodPatch] != 1 || len(writes) != 1 {
+		t.Errorf("expected 1 updated command, got %v", writes)
+	}
+
+	if writes := syncWith(changed[1:]); writes[http.MethodDelete] != 1 || len(writes) != 1 {
+		t.Errorf("expected 1 deleted command, got %v", writes)
+	}
+	if len(api.commands) != len(commands)-1 {
+		t.Errorf("expected %d registered commands, got %d", len(commands)-1, len(api.commands))
+	}
+}
@@ -1,20 +1,18 @@
 package gobot
 
 import (
-	"fmt"
-
 	"github.com/bwmarrin/discordgo"
 	"github.com/sirupsen/logrus"
 )
 
-var ComponentsHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot){
-	"selectTrack": func(s *discordgo.Session, i *discordgo.InteractionCreate, b *Bot) {
+var ComponentsHandlers = map[string]func(s Session, i *discordgo.InteractionCreate, b *Bot){
+	"selectTrack": func(s Session, i *discordgo.InteractionCreate, b *Bot) {
 		// Get user and track IDs
 		var response *discordgo.InteractionResponse
 		data := i.MessageComponentData().Values
 		if data == nil {
 			Logger.Warn("Expected user response but values are empty. Make sure the commands are set up properly.")
-			response := SingleInteractionResponse("An error occurred. The bot interaction appears to be set up incorrectly. Please try again later.",
+			response := SingleInteractionResponse(b.text(i, "component_setup_error", nil),
 				discordgo.InteractionResponseChannelMessageWithSource)
 			if err := s.InteractionRespond(i.Interaction, response); err != nil {
 				Logger.Warn("Failed to create interaction response: ", err)
@@ -36,23 +34,27 @@ var ComponentsHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inte
 		query := b.TrackMap[userID]
 		if query == nil {
 			selectLogger.Warn("User is not registered in the track map.")
-			response = SingleInteractionResponse("Could not find queries for the user. Please try a different query.",
+			response = SingleInteractionResponse(b.text(i, "select.no_queries", nil),
 				discordgo.InteractionResponseChannelMessageWithSource)
 		} else {
 			track := query[trackID]
 			if track == nil {
 				selectLogger.Warn("Track not found.")
-				response = SingleInteractionResponse("Could not find tracks for the user. Please try a different query",
+				response = SingleInteractionResponse(b.text(i, "select.no_track", nil),
 					discordgo.InteractionResponseChannelMessageWithSource)
 			} else {
 				selectLogger.Debug("Track ID found. Chosen title: ", track.Info().Title)
 				if err := b.Play(s, i, track); err != nil {
 					selectLogger.Warn("Something went wrong when trying to play chosen single-track: ", err)
-					response = SingleInteractionResponse("Could not query track. Please try a different query and make sure you are connected to a voice channel.",
-						discordgo.InteractionResponseChannelMessageWithSource)
+					response = SingleInteractionResponse(userMessage(b.style(i), err), discordgo.InteractionResponseChannelMessageWithSource)
+				} else if uri := track.Info().URI; uri == nil || !urlPattern.MatchString(*uri) {
+					// Local library tracks do not have a link
+					response = NewResponse(b.text(i, "select.querying", Args{"Title": track.Info().Title})).Embed(b.queuedEmbed(i, track)).
+						Visible(b.public(i)).Interaction(discordgo.InteractionResponseUpdateMessage)
 				} else {
-					response = SingleButtonInteractionResponse(fmt.Sprintf("Querying the track: %v", track.Info().Title), "Click here for the link",
-						*track.Info().URI, "🙈", discordgo.InteractionResponseUpdateMessage)
+					response = NewResponse(b.text(i, "select.querying", Args{"Title": track.Info().Title})).Embed(b.queuedEmbed(i, track)).
+						Button(b.text(i, "select.link", nil), *track.Info().URI, b.text(i, "select.link_emoji", nil)).Visible(b.public(i)).
+						Interaction(discordgo.InteractionResponseUpdateMessage)
 				}
 			}
 		}
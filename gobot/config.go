@@ -1,47 +1,393 @@
 package gobot
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/tkanos/gonfig"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
+// Configurations are layered with increasing precedence:
+// defaults from the default tag, the configuration file, environment variables from the env tag
+// and secret files referenced by the env tag with a _FILE suffix.
+// Fields with the reload tag are applied at runtime when the configuration is reloaded.
+// Fields with the legacy tag are also read from deprecated environment variables named like the field.
 type Configuration struct {
-	LogFile       string
-	LogLevel      string
-	LogFormat     string
-	LogTimeStamp  string
-	DiscordToken  string
-	LavalinkPW    string
-	LavalinkHost  string
-	LavalinkPort  string
-	LavalinkNode  string
-	ResumeKey     string
-	ResumeTimeOut int
-	Secure        bool
-}
-
-func getconfig(file string) (Configuration, error) {
+	LogFile           string   `env:"GOBOT_LOG_FILE" legacy:"true" reload:"true" default:"none" doc:"File to write logs to or none for stdout."`
+	LogLevel          string   `env:"GOBOT_LOG_LEVEL" legacy:"true" reload:"true" default:"info" enum:"debug,info,prod" doc:"Log level."`
+	LogFormat         string   `env:"GOBOT_LOG_FORMAT" legacy:"true" reload:"true" default:"text" enum:"text,json,plain" doc:"Log format."`
+	LogTimeStamp      string   `env:"GOBOT_LOG_TIME_STAMP" legacy:"true" reload:"true" default:"on" enum:"on,off" doc:"Log time stamps."`
+	DiscordToken      string   `env:"GOBOT_DISCORD_TOKEN" legacy:"true" required:"true" doc:"Token of the discord bot."`
+	LavalinkPW        string   `env:"GOBOT_LAVALINK_PW" legacy:"true" reload:"true" required:"true" doc:"Password of the lavalink node."`
+	LavalinkHost      string   `env:"GOBOT_LAVALINK_HOST" legacy:"true" reload:"true" default:"localhost" required:"true" doc:"Host name of the lavalink node."`
+	LavalinkPort      string   `env:"GOBOT_LAVALINK_PORT" legacy:"true" reload:"true" default:"2333" format:"port" doc:"Port of the lavalink node."`
+	LavalinkNode      string   `env:"GOBOT_LAVALINK_NODE" legacy:"true" reload:"true" default:"gobot" required:"true" doc:"Name of the lavalink node."`
+	ResumeKey         string   `env:"GOBOT_RESUME_KEY" legacy:"true" reload:"true" default:"gobot" required:"true" doc:"Key to resume the lavalink session with."`
+	ResumeTimeOut     int      `env:"GOBOT_RESUME_TIME_OUT" legacy:"true" reload:"true" default:"60" min:"1" max:"3600" doc:"Seconds lavalink keeps the session for resuming."`
+	Secure            bool     `env:"GOBOT_SECURE" legacy:"true" reload:"true" default:"false" doc:"Connect to lavalink via TLS."`
+	LibraryDir        string   `env:"GOBOT_LIBRARY_DIR" reload:"true" default:"none" format:"dir" doc:"Directory of local audio files or none."`
+	ThemeDir          string   `env:"GOBOT_THEME_DIR" reload:"true" default:"none" format:"dir" doc:"Directory of custom response themes or none. Every subdirectory is a theme with one JSON file per language."`
+	DevGuildIDs       []string `env:"GOBOT_DEV_GUILD_IDS" format:"snowflakes" doc:"Guilds to register commands in instantly instead of globally during development (comma separated in environment variables)."`
+	GuildSettingsFile string   `env:"GOBOT_GUILD_SETTINGS_FILE" default:"guilds.json" required:"true" doc:"File the per guild settings are stored in."`
+	HTTPAddress       string   `env:"GOBOT_HTTP_ADDRESS" default:"none" doc:"Address to serve /metrics, /healthz, /readyz and the admin API on, e.g. 127.0.0.1:8080, or none. Addresses like :8080 are reachable from other hosts."`
+	AdminToken        string   `env:"GOBOT_ADMIN_TOKEN" reload:"true" doc:"Bearer token of the admin API. Empty disables the API."`
+	DashboardURL      string   `env:"GOBOT_DASHBOARD_URL" reload:"true" default:"none" doc:"Public URL of HTTPAddress used in dashboard links, e.g. http://localhost:8080, or none to disable /dashboard."`
+}
+
+// All problems found while validating a configuration.
+type ConfigErrors []string
+
+func (e ConfigErrors) Error() string {
+	return "invalid configuration:\n\t" + strings.Join(e, "\n\t")
+}
+
+func defaultConfig() (Configuration, error) {
 	conf := Configuration{}
-	err := gonfig.GetConf(file, &conf)
-	return conf, err
+	values := reflect.ValueOf(&conf).Elem()
+	for i := 0; i < values.NumField(); i++ {
+		field := values.Type().Field(i)
+		if value, ok := field.Tag.Lookup("default"); ok {
+			if err := setConfigField(values.Field(i), value); err != nil {
+				return conf, fmt.Errorf("invalid default for %s: %w", field.Name, err)
+			}
+		}
+	}
+	return conf, nil
 }
 
-func ReadConfig(configFile string) Configuration {
-	Logger.Info("Setting up configurations")
+// Configuration file used without -config. Unlike other files it is optional.
+const DefaultConfigFile = "config.json"
+
+// Reads the configuration file on top of the given configuration. Only the default file may be missing.
+// The format is detected by the file extension and defaults to JSON. Unknown keys are rejected, so misspelled ones don't go unnoticed.
+func readConfigFile(file string, conf *Configuration) error {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) && filepath.Clean(file) == DefaultConfigFile {
+		Logger.Info("Configuration file " + file + " not found. Using defaults and environment variables.")
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-	// Read configurations from file or set to default values
-	conf, err := getconfig(configFile)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		values := map[string]any{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		return decodeConfigValues(values, conf)
+	case ".toml":
+		values := map[string]any{}
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		return decodeConfigValues(values, conf)
+	default:
+		return decodeConfigJSON(data, conf)
+	}
+}
+
+// Decodes YAML and TOML values via JSON so all formats share the same key matching and type semantics.
+func decodeConfigValues(values map[string]any, conf *Configuration) error {
+	data, err := json.Marshal(values)
 	if err != nil {
-		Logger.Fatal("Could not load configuration file " + configFile)
+		return err
+	}
+	return decodeConfigJSON(data, conf)
+}
+
+func decodeConfigJSON(data []byte, conf *Configuration) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(conf)
+}
+
+// Overrides configurations with environment variables and secret files, falling back to legacy variables.
+// Returns all invalid variables and unreadable secret files as ConfigErrors.
+func readConfigEnv(conf *Configuration) error {
+	var problems ConfigErrors
+	values := reflect.ValueOf(conf).Elem()
+	for i := 0; i < values.NumField(); i++ {
+		field := values.Type().Field(i)
+		env := field.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+
+		value, isSet := os.LookupEnv(env)
+		if secretFile, ok := os.LookupEnv(env + "_FILE"); ok {
+			if isSet {
+				problems = append(problems, "both "+env+" and "+env+"_FILE are set")
+				continue
+			}
+			secret, err := os.ReadFile(secretFile)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("could not read secret file for %s: %v", env, err))
+				continue
+			}
+			value, isSet = strings.TrimSpace(string(secret)), true
+		}
+
+		name := env
+		if legacyValue, ok := os.LookupEnv(field.Name); ok && field.Tag.Get("legacy") == "true" {
+			if isSet {
+				Logger.Warn("Ignoring deprecated environment variable " + field.Name + " since " + env + " is set.")
+			} else {
+				Logger.Warn("Environment variable " + field.Name + " is deprecated. Rename it to " + env + ".")
+				name, value, isSet = field.Name, legacyValue, true
+			}
+		}
+
+		if isSet {
+			if err := setConfigField(values.Field(i), value); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid value for %s: %v", name, err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func setConfigField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(number))
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return errors.New("unsupported configuration type " + field.Type().String())
+		}
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return errors.New("unsupported configuration type " + field.Kind().String())
+	}
+	return nil
+}
+
+func loadConfig(configFile string) (Configuration, error) {
+	conf, err := defaultConfig()
+	if err != nil {
+		return conf, err
+	}
+	if err := readConfigFile(configFile, &conf); err != nil {
+		return conf, fmt.Errorf("could not load configuration file %s: %w", configFile, err)
+	}
+	if err := readConfigEnv(&conf); err != nil {
+		return conf, err
+	}
+	return conf, nil
+}
+
+// Checks required fields, enum values, ranges and formats and reports all problems at once.
+func (c Configuration) Validate() error {
+	problems := validateFields(reflect.ValueOf(c), func(field reflect.StructField) string {
+		return field.Name + " (" + field.Tag.Get("env") + ")"
+	})
+	if len(problems) > 0 {
+		return problems
 	}
+	return nil
+}
+
+// Validates the fields of a struct by their required, enum, min, max and format tags.
+func validateFields(values reflect.Value, label func(field reflect.StructField) string) ConfigErrors {
+	var problems ConfigErrors
 
-	values := reflect.ValueOf(conf)
 	for i := 0; i < values.NumField(); i++ {
-		if v := values.Field(i).Interface(); v == "" {
-			Logger.Fatal("Value not set for environment variable " + values.Type().Field(i).Name)
+		field := values.Type().Field(i)
+		value := values.Field(i)
+		name := label(field)
+
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			problems = append(problems, name+" is required")
+			continue
+		}
+
+		if enum, ok := field.Tag.Lookup("enum"); ok && !containsString(strings.Split(enum, ","), value.String()) {
+			problems = append(problems, fmt.Sprintf("%s is %q but has to be one of %s", name, value.String(), enum))
+		}
+
+		if value.Kind() == reflect.Int {
+			if min, ok := field.Tag.Lookup("min"); ok {
+				if bound, _ := strconv.ParseInt(min, 10, 64); value.Int() < bound {
+					problems = append(problems, fmt.Sprintf("%s is %d but has to be at least %s", name, value.Int(), min))
+				}
+			}
+			if max, ok := field.Tag.Lookup("max"); ok {
+				if bound, _ := strconv.ParseInt(max, 10, 64); value.Int() > bound {
+					problems = append(problems, fmt.Sprintf("%s is %d but has to be at most %s", name, value.Int(), max))
+				}
+			}
+		}
+
+		switch field.Tag.Get("format") {
+		case "port":
+			if port, err := strconv.Atoi(value.String()); err != nil || port < 1 || port > 65535 {
+				problems = append(problems, fmt.Sprintf("%s is %q but has to be a port between 1 and 65535", name, value.String()))
+			}
+		case "dir":
+			if value.String() != "none" {
+				if info, err := os.Stat(value.String()); err != nil || !info.IsDir() {
+					problems = append(problems, fmt.Sprintf("%s is %q but has to be an existing directory or none", name, value.String()))
+				}
+			}
+		case "theme":
+			if !containsString(Messages.Themes(), value.String()) {
+				problems = append(problems, fmt.Sprintf("%s is %q but has to be one of %s", name, value.String(), strings.Join(Messages.Themes(), ",")))
+			}
+		case "commands":
+			var names []string
+			for _, command := range ApplicationCommands() {
+				names = append(names, command.Name)
+			}
+			for _, command := range commandList(value.String()) {
+				if !containsString(names, command) {
+					problems = append(problems, fmt.Sprintf("%s contains %q but may only contain the commands %s", name, command, strings.Join(names, ",")))
+				}
+			}
+		case "snowflake":
+			if _, err := strconv.ParseUint(value.String(), 10, 64); value.String() != "" && err != nil {
+				problems = append(problems, fmt.Sprintf("%s is %q but has to be a discord ID", name, value.String()))
+			}
+		case "snowflakes":
+			for n := 0; n < value.Len(); n++ {
+				if _, err := strconv.ParseUint(value.Index(n).String(), 10, 64); err != nil {
+					problems = append(problems, fmt.Sprintf("%s contains %q but may only contain discord IDs", name, value.Index(n).String()))
+				}
+			}
 		}
 	}
 
+	return problems
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Loads and validates the configuration without exiting on errors.
+// Problems of environment variables are reported together with the validation problems.
+func LoadConfig(configFile string) (Configuration, error) {
+	conf, err := loadConfig(configFile)
+	var problems ConfigErrors
+	if err != nil && !errors.As(err, &problems) {
+		return conf, err
+	}
+	var validationProblems ConfigErrors
+	if err := conf.Validate(); errors.As(err, &validationProblems) {
+		problems = append(problems, validationProblems...)
+	}
+	if len(problems) > 0 {
+		return conf, problems
+	}
+	return conf, nil
+}
+
+func ReadConfig(configFile string) Configuration {
+	Logger.Info("Setting up configurations")
+
+	conf, err := LoadConfig(configFile)
+	if err != nil {
+		Logger.Fatal("Could not load configurations: ", err)
+	}
+
 	return conf
 }
+
+// Generates a JSON schema documenting every configuration key.
+func ConfigSchema() ([]byte, error) {
+	properties := map[string]any{}
+	var required []string
+
+	configType := reflect.TypeOf(Configuration{})
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		property := map[string]any{
+			"description": field.Tag.Get("doc"),
+			"x-env":       field.Tag.Get("env"),
+			"x-env-file":  field.Tag.Get("env") + "_FILE",
+			"x-reload":    field.Tag.Get("reload") == "true",
+		}
+		if field.Tag.Get("legacy") == "true" {
+			property["x-env-deprecated"] = field.Name
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Int:
+			property["type"] = "integer"
+		case reflect.Bool:
+			property["type"] = "boolean"
+		case reflect.Slice:
+			property["type"] = "array"
+			property["items"] = map[string]any{"type": "string"}
+		default:
+			property["type"] = "string"
+		}
+
+		if value, ok := field.Tag.Lookup("default"); ok {
+			defaultValue := reflect.New(field.Type).Elem()
+			if err := setConfigField(defaultValue, value); err != nil {
+				return nil, err
+			}
+			property["default"] = defaultValue.Interface()
+		}
+		if field.Tag.Get("required") == "true" {
+			required = append(required, field.Name)
+		}
+		if enum, ok := field.Tag.Lookup("enum"); ok {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		if min, ok := field.Tag.Lookup("min"); ok {
+			property["minimum"], _ = strconv.Atoi(min)
+		}
+		if max, ok := field.Tag.Lookup("max"); ok {
+			property["maximum"], _ = strconv.Atoi(max)
+		}
+		if field.Tag.Get("format") == "port" {
+			property["pattern"] = "^[0-9]{1,5}$"
+		}
+
+		properties[field.Name] = property
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "gobot configuration",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, "", "    ")
+}
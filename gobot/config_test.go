@@ -0,0 +1,280 @@
+package gobot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigFileFormats(t *testing.T) {
+	expected, err := loadConfig(filepath.Join("..", "example.json"))
+	if err != nil {
+		t.Fatalf("loading example.json: %v", err)
+	}
+
+	for _, file := range []string{"example.yaml", "example.toml"} {
+		t.Run(file, func(t *testing.T) {
+			conf, err := loadConfig(filepath.Join("..", file))
+			if err != nil {
+				t.Fatalf("loading %s: %v", file, err)
+			}
+			if !reflect.DeepEqual(conf, expected) {
+				t.Errorf("%s differs from example.json:\ngot  %+v\nwant %+v", file, conf, expected)
+			}
+		})
+	}
+}
+
+func TestReadConfigFilePartial(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"DiscordToken": "token", "ResumeTimeOut": 5, "Secure": true}`,
+		"config.yml":  "DiscordToken: token\nResumeTimeOut: 5\nSecure: true\n",
+		"config.toml": "DiscordToken = \"token\"\nResumeTimeOut = 5\nSecure = true\n",
+	}
+
+	expected, err := defaultConfig()
+	if err != nil {
+		t.Fatalf("loading defaults: %v", err)
+	}
+	expected.DiscordToken = "token"
+	expected.ResumeTimeOut = 5
+	expected.Secure = true
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(dir, name)
+			if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			conf, err := defaultConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := readConfigFile(file, &conf); err != nil {
+				t.Fatalf("reading %s: %v", name, err)
+			}
+			if !reflect.DeepEqual(conf, expected) {
+				t.Errorf("%s:\ngot  %+v\nwant %+v", name, conf, expected)
+			}
+		})
+	}
+}
+
+func TestReadConfigFileUnknownKeys(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"DiscordToken": "token", "LogLevl": "debug"}`,
+		"config.yml":  "DiscordToken: token\nLogLevl: debug\n",
+		"config.toml": "DiscordToken = \"token\"\nLogLevl = \"debug\"\n",
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(dir, name)
+			if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			conf, err := defaultConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := readConfigFile(file, &conf); err == nil || !strings.Contains(err.Error(), "LogLevl") {
+				t.Errorf("expected the misspelled key to be reported, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadConfigEnvReportsAllProblems(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "token")
+	if err := os.WriteFile(secret, []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOBOT_DISCORD_TOKEN", "token")
+	t.Setenv("GOBOT_DISCORD_TOKEN_FILE", secret)
+	t.Setenv("GOBOT_LAVALINK_PW_FILE", filepath.Join(dir, "missing"))
+	t.Setenv("GOBOT_RESUME_TIME_OUT", "soon")
+	t.Setenv("GOBOT_SECURE", "maybe")
+
+	conf, err := defaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = readConfigEnv(&conf)
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	for _, env := range []string{"GOBOT_DISCORD_TOKEN_FILE", "GOBOT_LAVALINK_PW", "GOBOT_RESUME_TIME_OUT", "GOBOT_SECURE"} {
+		if !strings.Contains(err.Error(), env) {
+			t.Errorf("%s is not reported in %v", env, err)
+		}
+	}
+	if len(problems) != 4 {
+		t.Errorf("expected 4 problems, got %d: %v", len(problems), err)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		secrets map[string]string // environment variables with a _FILE suffix, mapped to the content of their file
+		check   func(conf Configuration) bool
+		err     string
+	}{
+		{
+			name: "defaults apply",
+			file: `{"DiscordToken": "token"}`,
+			check: func(conf Configuration) bool {
+				return conf.LogLevel == "info" && conf.LavalinkPort == "2333" && conf.ResumeTimeOut == 60
+			},
+		},
+		{
+			name:  "file overrides defaults",
+			file:  `{"LogLevel": "debug"}`,
+			check: func(conf Configuration) bool { return conf.LogLevel == "debug" },
+		},
+		{
+			name: "env overrides file",
+			file: `{"LogLevel": "debug", "ResumeTimeOut": 5, "DevGuildIDs": ["1"]}`,
+			env:  map[string]string{"GOBOT_LOG_LEVEL": "prod", "GOBOT_RESUME_TIME_OUT": "30", "GOBOT_DEV_GUILD_IDS": "2, 3"},
+			check: func(conf Configuration) bool {
+				return conf.LogLevel == "prod" && conf.ResumeTimeOut == 30 && reflect.DeepEqual(conf.DevGuildIDs, []string{"2", "3"})
+			},
+		},
+		{
+			name:    "secret files are read",
+			file:    `{"DiscordToken": "from file"}`,
+			secrets: map[string]string{"GOBOT_DISCORD_TOKEN": "secret token\n", "GOBOT_LAVALINK_PW": "password"},
+			check: func(conf Configuration) bool {
+				return conf.DiscordToken == "secret token" && conf.LavalinkPW == "password"
+			},
+		},
+		{
+			name: "legacy variables apply",
+			file: `{"LogLevel": "debug"}`,
+			env:  map[string]string{"DiscordToken": "legacy token", "LogLevel": "prod", "ResumeTimeOut": "5"},
+			check: func(conf Configuration) bool {
+				return conf.DiscordToken == "legacy token" && conf.LogLevel == "prod" && conf.ResumeTimeOut == 5
+			},
+		},
+		{
+			name:  "prefixed variables override legacy ones",
+			env:   map[string]string{"GOBOT_LOG_LEVEL": "prod", "LogLevel": "debug"},
+			check: func(conf Configuration) bool { return conf.LogLevel == "prod" },
+		},
+		{
+			name:  "fields added later have no legacy variables",
+			env:   map[string]string{"AdminToken": "secret"},
+			check: func(conf Configuration) bool { return conf.AdminToken == "" },
+		},
+		{
+			name: "invalid legacy variables are reported by their name",
+			env:  map[string]string{"ResumeTimeOut": "soon"},
+			err:  "invalid value for ResumeTimeOut",
+		},
+		{
+			name:    "variable and secret file are rejected",
+			env:     map[string]string{"GOBOT_DISCORD_TOKEN": "token"},
+			secrets: map[string]string{"GOBOT_DISCORD_TOKEN": "secret token"},
+			err:     "both GOBOT_DISCORD_TOKEN and GOBOT_DISCORD_TOKEN_FILE are set",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "config.json")
+			if test.file == "" {
+				test.file = "{}"
+			}
+			if err := os.WriteFile(file, []byte(test.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+			for env, value := range test.env {
+				t.Setenv(env, value)
+			}
+			for env, content := range test.secrets {
+				secret := filepath.Join(dir, env)
+				if err := os.WriteFile(secret, []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv(env+"_FILE", secret)
+			}
+
+			conf, err := loadConfig(file)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loading configuration: %v", err)
+			}
+			if !test.check(conf) {
+				t.Errorf("unexpected configuration %+v", conf)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing configuration file to fail, got %v", err)
+	}
+
+	// Without -config the default file is read if it exists
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(dir) })
+	if conf, err := loadConfig(DefaultConfigFile); err != nil || conf.LavalinkPort != "2333" {
+		t.Errorf("expected defaults without the default file, got %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	conf, err := defaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.DiscordToken = ""
+	conf.LogLevel = "verbose"
+	conf.ResumeTimeOut = 5000
+	conf.LavalinkPort = "abc"
+	conf.LibraryDir = filepath.Join(t.TempDir(), "missing")
+	conf.DevGuildIDs = []string{"1", "x"}
+
+	err = conf.Validate()
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	for _, field := range []string{"DiscordToken", "LavalinkPW", "LogLevel", "ResumeTimeOut", "LavalinkPort", "LibraryDir", "DevGuildIDs"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("%s is not reported in %v", field, err)
+		}
+	}
+	if len(problems) != 7 {
+		t.Errorf("expected 7 problems, got %d: %v", len(problems), err)
+	}
+
+	conf.ResumeTimeOut = 0
+	if err := conf.Validate(); !strings.Contains(err.Error(), "ResumeTimeOut (GOBOT_RESUME_TIME_OUT) is 0 but has to be at least 1") {
+		t.Errorf("expected the minimum of ResumeTimeOut to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package gobot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/gorilla/websocket"
+)
+
+// Dashboard links are bearer tokens, so they expire soon
+const dashboardTokenTTL = 30 * time.Minute
+
+// Number of search results shown in the dashboard
+const dashboardSearchLimit = 5
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// Web dashboard of the guild queues. Links are signed with a secret generated on startup,
+// so they become invalid when the bot restarts.
+type Dashboard struct {
+	secret    []byte
+	clients   map[string]map[chan struct{}]bool // maps guild ID to update channels of connected clients
+	clientsMu sync.Mutex
+}
+
+// Claims of a dashboard link.
+type DashboardSession struct {
+	GuildID string `json:"guild_id"`
+	UserID  string `json:"user_id"`
+	DJ      bool   `json:"dj"`
+	Expires int64  `json:"expires"`
+}
+
+var dashboardUpgrader = websocket.Upgrader{}
+
+func NewDashboard() (*Dashboard, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &Dashboard{
+		secret:  secret,
+		clients: map[string]map[chan struct{}]bool{},
+	}, nil
+}
+
+// Creates a signed dashboard link for a member of a guild.
+func (d *Dashboard) Link(baseURL string, guildID string, userID string, dj bool) (string, error) {
+	payload, err := json.Marshal(DashboardSession{
+		GuildID: guildID,
+		UserID:  userID,
+		DJ:      dj,
+		Expires: time.Now().Add(dashboardTokenTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	token := encoded + "." + base64.RawURLEncoding.EncodeToString(d.sign(encoded))
+	return strings.TrimSuffix(baseURL, "/") + "/dashboard/?token=" + url.QueryEscape(token), nil
+}
+
+func (d *Dashboard) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func (d *Dashboard) verify(token string) (DashboardSession, error) {
+	var session DashboardSession
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return session, errors.New("malformed token")
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, d.sign(payload)) {
+		return session, errors.New("invalid token")
+	}
+
+	decodedPayload, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return session, errors.New("malformed token")
+	}
+	if err := json.Unmarshal(decodedPayload, &session); err != nil {
+		return session, errors.New("malformed token")
+	}
+	if time.Now().Unix() > session.Expires {
+		return session, errors.New("link expired. Use /dashboard for a new one")
+	}
+	return session, nil
+}
+
+// Tells all clients of the guild to fetch the current state. Never blocks, pending updates are merged.
+func (d *Dashboard) notify(guildID string) {
+	d.clientsMu.Lock()
+	defer d.clientsMu.Unlock()
+	for updates := range d.clients[guildID] {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (d *Dashboard) subscribe(guildID string) chan struct{} {
+	d.clientsMu.Lock()
+	defer d.clientsMu.Unlock()
+	updates := make(chan struct{}, 1)
+	if d.clients[guildID] == nil {
+		d.clients[guildID] = map[chan struct{}]bool{}
+	}
+	d.clients[guildID][updates] = true
+	return updates
+}
+
+func (d *Dashboard) unsubscribe(guildID string, updates chan struct{}) {
+	d.clientsMu.Lock()
+	defer d.clientsMu.Unlock()
+	delete(d.clients[guildID], updates)
+	if len(d.clients[guildID]) == 0 {
+		delete(d.clients, guildID)
+	}
+}
+
+// Registers the dashboard page and its API.
+func (b *Bot) handleDashboard(mux *http.ServeMux) {
+	static, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		Logger.Panic("Dashboard files are missing: ", err)
+	}
+	mux.Handle("/dashboard/", http.StripPrefix("/dashboard/", http.FileServer(http.FS(static))))
+	mux.HandleFunc("/dashboard/api/", b.dashboardAPI)
+}
+
+// API of the dashboard page. The guild is taken from the token, so members only see their own guild.
+//
+//	GET  /dashboard/api/session         claims of the token
+//	GET  /dashboard/api/state           player of the guild
+//	GET  /dashboard/api/updates         websocket pushing the player on every change
+//	GET  /dashboard/api/search?query=   search results
+//	POST /dashboard/api/add             queue {"query"}
+//	POST /dashboard/api/move            move a queued song {"from", "to"}, DJ only
+//	POST /dashboard/api/remove          remove a queued song {"index"}, DJ only
+func (b *Bot) dashboardAPI(w http.ResponseWriter, r *http.Request) {
+	session, err := b.Dashboard.verify(r.URL.Query().Get("token"))
+	if err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	action := strings.TrimPrefix(r.URL.Path, "/dashboard/api/")
+	dashboardLogger := Logger.WithField("dashboard", action).WithField("userID", session.UserID).WithField("guildID", session.GuildID)
+
+	var request struct {
+		Query string `json:"query"`
+		From  int    `json:"from"`
+		To    int    `json:"to"`
+		Index int    `json:"index"`
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAPIError(w, http.StatusBadRequest, errors.New("invalid JSON body"))
+			return
+		}
+	}
+
+	switch {
+	case action == "session" && r.Method == http.MethodGet:
+		writeAPI(w, http.StatusOK, session)
+		return
+	case action == "state" && r.Method == http.MethodGet:
+	case action == "updates" && r.Method == http.MethodGet:
+		b.dashboardUpdates(w, r, session.GuildID)
+		return
+	case action == "search" && r.Method == http.MethodGet:
+		results, err := b.searchTracks(r.Context(), session.GuildID, r.URL.Query().Get("query"))
+		if err != nil {
+			writeAPIError(w, apiStatus(err), err)
+			return
+		}
+		writeAPI(w, http.StatusOK, results)
+		return
+	case action == "add" && r.Method == http.MethodPost:
+		err = b.dashboardAdd(r.Context(), session, request.Query)
+	case (action == "move" || action == "remove") && r.Method == http.MethodPost:
+		manager, ok := b.manager(session.GuildID)
+		switch {
+		case !session.DJ:
+			writeAPIError(w, http.StatusForbidden, errors.New("only members with the DJ role can do that"))
+			return
+		case !ok:
+			err = ErrNoPlayer
+		case action == "move":
+			err = manager.MoveQueue(request.From, request.To)
+		default:
+			_, err = manager.RemoveQueue(request.Index)
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, errors.New("unknown endpoint"))
+		return
+	}
+	if err != nil {
+		dashboardLogger.Warn("Dashboard request failed: ", err)
+		writeAPIError(w, apiStatus(err), err)
+		return
+	}
+
+	writeAPI(w, http.StatusOK, b.playerState(session.GuildID))
+}
+
+// Pushes the player of the guild over a websocket whenever it changes.
+func (b *Bot) dashboardUpdates(w http.ResponseWriter, r *http.Request, guildID string) {
+	conn, err := dashboardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		Logger.Debug("Dashboard websocket upgrade failed: ", err)
+		return
+	}
+	defer conn.Close()
+
+	updates := b.Dashboard.subscribe(guildID)
+	defer b.Dashboard.unsubscribe(guildID, updates)
+
+	// The client does not send anything, reading only notices when it disconnects
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		if err := conn.WriteJSON(b.playerState(guildID)); err != nil {
+			return
+		}
+		select {
+		case <-updates:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// Queues tracks and joins the voice channel of the member if the bot is not connected yet.
+func (b *Bot) dashboardAdd(ctx context.Context, session DashboardSession, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return errors.New("query is required")
+	}
+
+	if state, _ := b.Session.VoiceState(session.GuildID, b.Session.UserID()); state == nil {
+		voiceState, err := b.Session.VoiceState(session.GuildID, session.UserID)
+		if err != nil {
+			return ErrUserNotInVoice
+		}
+		if err := b.Session.ChannelVoiceJoinManual(session.GuildID, voiceState.ChannelID, false, false); err != nil {
+			return fmt.Errorf("%w: %v", ErrVoiceJoin, err)
+		}
+	}
+
+	tracks, err := b.loadTracks(ctx, session.GuildID, "dashboard", query)
+	if err != nil {
+		return err
+	}
+	return b.play(b.Session, session.GuildID, tracks...)
+}
+
+func (b *Bot) searchTracks(ctx context.Context, guildID string, query string) ([]TrackState, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+	if !urlPattern.MatchString(query) {
+		query = lavalink.SearchType(b.Settings.Get(guildID).SearchSource).Apply(query)
+	}
+
+	results := []TrackState{}
+	add := func(tracks ...lavalink.AudioTrack) {
+		for _, track := range tracks {
+			if len(results) < dashboardSearchLimit {
+				results = append(results, newTrackState(track))
+			}
+		}
+	}
+	err := loadItemTimed(ctx, b.Lavalink, "dashboard", query, lavalink.NewResultHandler(
+		func(track lavalink.AudioTrack) { add(track) },
+		func(playlist lavalink.AudioPlaylist) { add(playlist.Tracks()...) },
+		func(tracks []lavalink.AudioTrack) { add(tracks...) },
+		func() {},
+		func(ex lavalink.FriendlyException) {},
+	))
+	return results, lavalinkError("load", err)
+}
+
+// Player of the guild or an empty player if the bot is not connected.
+func (b *Bot) playerState(guildID string) PlayerState {
+	if manager, ok := b.manager(guildID); ok {
+		return newPlayerState(manager)
+	}
+	return PlayerState{
+		GuildID: guildID,
+		Mode:    repeatingModeNames[RepeatingModeOff],
+		Queue:   []TrackState{},
+	}
+}
@@ -0,0 +1,188 @@
+"use strict";
+
+// The signed token of the dashboard link authenticates every request. It is kept for reloads of the tab.
+const token = new URLSearchParams(location.search).get("token") || sessionStorage.getItem("token") || "";
+sessionStorage.setItem("token", token);
+const api = (path) => "api/" + path + (path.includes("?") ? "&" : "?") + "token=" + encodeURIComponent(token);
+
+let session = {dj: false};
+let player = null;
+let receivedAt = 0;
+
+const $ = (id) => document.getElementById(id);
+
+function showError(message) {
+    $("error").textContent = message;
+    $("error").hidden = !message;
+}
+
+async function request(path, body) {
+    const options = body === undefined ? {} : {
+        method: "POST",
+        headers: {"Content-Type": "application/json"},
+        body: JSON.stringify(body),
+    };
+    const response = await fetch(api(path), options);
+    const data = await response.json();
+    if (!response.ok) {
+        throw new Error(data.error);
+    }
+    return data;
+}
+
+async function run(path, body) {
+    try {
+        showError("");
+        const state = await request(path, body);
+        if (state.guild_id) {
+            render(state);
+        }
+        return state;
+    } catch (e) {
+        showError(e.message);
+    }
+}
+
+function formatTime(ms) {
+    const seconds = Math.floor(ms / 1000);
+    const parts = [Math.floor(seconds / 60) % 60, seconds % 60].map((n) => String(n).padStart(2, "0"));
+    if (seconds >= 3600) {
+        parts.unshift(Math.floor(seconds / 3600));
+    }
+    return parts.join(":");
+}
+
+function trackLabel(track) {
+    const length = track.is_stream ? "live" : formatTime(track.length_ms);
+    return `${track.title} — ${track.author} (${length})`;
+}
+
+function trackElement(track) {
+    const element = document.createElement(track.uri ? "a" : "span");
+    element.textContent = trackLabel(track);
+    if (track.uri) {
+        element.href = track.uri;
+        element.target = "_blank";
+        element.rel = "noreferrer";
+    }
+    return element;
+}
+
+function render(state) {
+    player = state;
+    receivedAt = Date.now();
+
+    const track = $("track");
+    track.replaceChildren(state.track ? trackElement(state.track) : "Nothing is playing.");
+    $("details").textContent = state.track
+        ? `Repeat: ${state.mode}` + (state.autoplay ? " · Autoplay" : "") + (state.paused ? " · Paused" : "")
+        : "";
+    renderProgress();
+
+    const queue = $("queue");
+    queue.replaceChildren(...state.queue.map((queued, index) => {
+        const item = document.createElement("li");
+        item.append(trackElement(queued));
+        if (session.dj) {
+            item.draggable = true;
+            item.addEventListener("dragstart", (e) => e.dataTransfer.setData("text/plain", String(index)));
+            item.addEventListener("dragover", (e) => {
+                e.preventDefault();
+                item.classList.add("drag-over");
+            });
+            item.addEventListener("dragleave", () => item.classList.remove("drag-over"));
+            item.addEventListener("drop", (e) => {
+                e.preventDefault();
+                item.classList.remove("drag-over");
+                const from = Number(e.dataTransfer.getData("text/plain"));
+                if (from !== index) {
+                    run("move", {from: from, to: index});
+                }
+            });
+
+            const remove = document.createElement("button");
+            remove.className = "remove";
+            remove.title = "Remove";
+            remove.textContent = "✕";
+            remove.addEventListener("click", () => run("remove", {index: index}));
+            item.append(remove);
+        }
+        return item;
+    }));
+    if (state.queue.length === 0) {
+        const empty = document.createElement("li");
+        empty.className = "muted";
+        empty.textContent = "The queue is empty.";
+        queue.append(empty);
+    }
+}
+
+// Lavalink only reports the position every few seconds, so it is advanced locally in between
+function renderProgress() {
+    const progress = $("progress");
+    if (!player || !player.track || player.track.is_stream) {
+        progress.hidden = true;
+        return;
+    }
+    const elapsed = player.paused ? 0 : Date.now() - receivedAt;
+    const position = Math.min(player.position_ms + elapsed, player.track.length_ms);
+    progress.hidden = false;
+    progress.max = player.track.length_ms;
+    progress.value = position;
+    progress.title = `${formatTime(position)} / ${formatTime(player.track.length_ms)}`;
+}
+
+function connect() {
+    const url = new URL(api("updates"), location.href);
+    url.protocol = location.protocol === "https:" ? "wss:" : "ws:";
+    const socket = new WebSocket(url);
+
+    socket.addEventListener("open", () => {
+        $("connection").textContent = "live";
+        $("connection").className = "online";
+    });
+    socket.addEventListener("message", (e) => render(JSON.parse(e.data)));
+    socket.addEventListener("close", () => {
+        $("connection").textContent = "offline";
+        $("connection").className = "offline";
+        // Expired links can't reconnect, the session check shows why
+        request("session").then(() => setTimeout(connect, 2000), (e) => showError(e.message));
+    });
+}
+
+$("search").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    showError("");
+    const results = $("results");
+    try {
+        const tracks = await request("search?query=" + encodeURIComponent($("query").value));
+        results.replaceChildren(...tracks.map((track) => {
+            const item = document.createElement("li");
+            const add = document.createElement("button");
+            add.textContent = "Add";
+            add.addEventListener("click", () => run("add", {query: track.uri || $("query").value}));
+            item.append(trackElement(track), add);
+            return item;
+        }));
+        if (tracks.length === 0) {
+            results.replaceChildren("No matches found.");
+        }
+    } catch (error) {
+        showError(error.message);
+    }
+});
+
+async function start() {
+    // Keep the token out of the address bar and browser history
+    history.replaceState(null, "", location.pathname);
+    try {
+        session = await request("session");
+        $("dj-hint").hidden = session.dj;
+        connect();
+        setInterval(renderProgress, 1000);
+    } catch (e) {
+        showError(e.message);
+    }
+}
+
+start();
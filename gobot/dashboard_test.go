@@ -0,0 +1,128 @@
+package gobot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Token of a dashboard link for the test user.
+func dashboardToken(t *testing.T, bot *Bot, guildID string, dj bool) string {
+	t.Helper()
+	link, err := bot.Dashboard.Link("http://localhost", guildID, testUserID, dj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url.QueryEscape(parsed.Query().Get("token"))
+}
+
+func TestDashboardAuthorization(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	token := dashboardToken(t, bot, testGuildID, false)
+
+	payload, err := json.Marshal(DashboardSession{GuildID: testGuildID, UserID: testUserID, Expires: time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	expired := encoded + "." + base64.RawURLEncoding.EncodeToString(bot.Dashboard.sign(encoded))
+
+	other, err := NewDashboard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := other.Link("http://localhost", testGuildID, testUserID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, foreignToken, _ := strings.Cut(foreign, "token=")
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{name: "missing token", target: "/dashboard/api/state", status: http.StatusUnauthorized},
+		{name: "malformed token", target: "/dashboard/api/state?token=abc", status: http.StatusUnauthorized},
+		{name: "expired token", target: "/dashboard/api/state?token=" + expired, status: http.StatusUnauthorized},
+		{name: "token of another bot", target: "/dashboard/api/state?token=" + foreignToken, status: http.StatusUnauthorized},
+		{name: "admin token", target: "/dashboard/api/state?token=secret", status: http.StatusUnauthorized},
+		{name: "valid token", target: "/dashboard/api/state?token=" + token, status: http.StatusOK},
+		{name: "unknown endpoint", target: "/dashboard/api/unknown?token=" + token, status: http.StatusNotFound},
+	}
+
+	bot.Config.AdminToken = "secret"
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := serveJSON(t, bot.dashboardAPI, http.MethodGet, test.target, "", "", nil); status != test.status {
+				t.Errorf("expected status %d, got %d", test.status, status)
+			}
+		})
+	}
+}
+
+func TestDashboardAPI(t *testing.T) {
+	bot, _, link := newTestBot(t)
+	for _, identifier := range []string{"a", "b", "c"} {
+		link.tracks["https://example.com/"+identifier] = []lavalink.AudioTrack{testTrack(identifier, strings.ToUpper(identifier), lavalink.Minute)}
+	}
+	member := dashboardToken(t, bot, testGuildID, false)
+	dj := dashboardToken(t, bot, testGuildID, true)
+
+	request := func(method string, action string, token string, body string, result interface{}) int {
+		t.Helper()
+		return serveJSON(t, bot.dashboardAPI, method, "/dashboard/api/"+action+"?token="+token, "", body, result)
+	}
+
+	var session DashboardSession
+	if status := request(http.MethodGet, "session", dj, "", &session); status != http.StatusOK || session.GuildID != testGuildID || !session.DJ {
+		t.Errorf("expected the claims of the token, got %d: %+v", status, session)
+	}
+
+	var player PlayerState
+	if status := request(http.MethodGet, "state", member, "", &player); status != http.StatusOK || player.Track != nil || player.GuildID != testGuildID {
+		t.Errorf("expected an empty player, got %d: %+v", status, player)
+	}
+
+	for _, identifier := range []string{"a", "b", "c"} {
+		if status := request(http.MethodPost, "add", member, `{"query": "https://example.com/`+identifier+`"}`, &player); status != http.StatusOK {
+			t.Fatalf("expected %s to be added, got %d", identifier, status)
+		}
+	}
+	if player.Track == nil || player.Track.Identifier != "a" || len(player.Queue) != 2 {
+		t.Errorf("expected a playing track and 2 queued tracks, got %+v", player)
+	}
+	if status := request(http.MethodPost, "add", member, `{"query": " "}`, nil); status != http.StatusBadRequest {
+		t.Errorf("expected an empty query to be rejected, got %d", status)
+	}
+
+	var results []TrackState
+	if status := request(http.MethodGet, "search", member, "", &results); status != http.StatusBadRequest {
+		t.Errorf("expected a search without query to be rejected, got %d", status)
+	}
+
+	if status := request(http.MethodPost, "move", member, `{"from": 1, "to": 0}`, nil); status != http.StatusForbidden {
+		t.Errorf("expected members without DJ role to be forbidden to move songs, got %d", status)
+	}
+	if status := request(http.MethodPost, "move", dj, `{"from": 1, "to": 0}`, &player); status != http.StatusOK || player.Queue[0].Identifier != "c" {
+		t.Errorf("expected the DJ to move songs, got %d: %+v", status, player)
+	}
+	if status := request(http.MethodPost, "remove", dj, `{"index": 0}`, &player); status != http.StatusOK || len(player.Queue) != 1 || player.Queue[0].Identifier != "b" {
+		t.Errorf("expected the DJ to remove songs, got %d: %+v", status, player)
+	}
+
+	// The guild comes from the token, so members only see their own guild
+	if status := request(http.MethodGet, "state", dashboardToken(t, bot, "1", true), "", &player); status != http.StatusOK || player.GuildID != "1" || player.Track != nil {
+		t.Errorf("expected the empty player of the other guild, got %d: %+v", status, player)
+	}
+}
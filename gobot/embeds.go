@@ -0,0 +1,128 @@
+package gobot
+
+import (
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Serves the icons of track sources as PNG, since discord does not show the .ico favicons of the sites
+const faviconURL = "https://www.google.com/s2/favicons?sz=64&domain="
+
+var youtubeIDPattern = regexp.MustCompile(`^[-_a-zA-Z0-9]{11}$`)
+
+// Display names and sites of the lavalink sources shown in the footer of track embeds
+var trackSources = map[string]struct{ Name, Site string }{
+	"youtube":    {Name: "YouTube", Site: "youtube.com"},
+	"soundcloud": {Name: "SoundCloud", Site: "soundcloud.com"},
+	"bandcamp":   {Name: "Bandcamp", Site: "bandcamp.com"},
+	"twitch":     {Name: "Twitch", Site: "twitch.tv"},
+	"vimeo":      {Name: "Vimeo", Site: "vimeo.com"},
+	"http":       {Name: "HTTP"},
+	"local":      {Name: "Library"},
+}
+
+// Embed showing a track with its artwork, author, duration and source. Build it with NewTrackEmbed.
+type TrackEmbed struct {
+	style Style
+	embed *discordgo.MessageEmbed
+}
+
+func NewTrackEmbed(style Style, track TrackState) *TrackEmbed {
+	e := &TrackEmbed{
+		style: style,
+		embed: &discordgo.MessageEmbed{Title: truncate(track.Title, 256)},
+	}
+	if urlPattern.MatchString(track.URI) {
+		e.embed.URL = track.URI
+	}
+	if artwork := trackArtwork(track); artwork != "" {
+		e.embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: artwork}
+	}
+
+	if track.Author != "" {
+		e.field("embed.author", truncate(track.Author, 1024))
+	}
+	e.field("embed.duration", trackDuration(style, track))
+
+	if source, ok := trackSources[track.Source]; ok {
+		e.embed.Footer = &discordgo.MessageEmbedFooter{Text: source.Name}
+		if source.Site != "" {
+			e.embed.Footer.IconURL = faviconURL + source.Site
+		}
+	} else if track.Source != "" {
+		e.embed.Footer = &discordgo.MessageEmbedFooter{Text: track.Source}
+	}
+	return e
+}
+
+// Length of the track, or that it is live for streams.
+func trackDuration(style Style, track TrackState) string {
+	if track.IsStream {
+		return Messages.Text(style, "embed.live", nil)
+	}
+	return formatTimestamp(lavalink.Duration(track.LengthMs))
+}
+
+// Artwork of the track. Lavalink only knows the artwork of YouTube videos, which is derived from the video ID.
+func trackArtwork(track TrackState) string {
+	if track.Source == "youtube" && youtubeIDPattern.MatchString(track.Identifier) {
+		return "https://img.youtube.com/vi/" + track.Identifier + "/hqdefault.jpg"
+	}
+	return ""
+}
+
+func (e *TrackEmbed) field(key string, value string) {
+	e.embed.Fields = append(e.embed.Fields, &discordgo.MessageEmbedField{
+		Name:   Messages.Text(e.style, key, nil),
+		Value:  value,
+		Inline: true,
+	})
+}
+
+// Shows the text above the title, e.g. that the track is playing now.
+func (e *TrackEmbed) Heading(text string) *TrackEmbed {
+	e.embed.Author = &discordgo.MessageEmbedAuthor{Name: text}
+	return e
+}
+
+// Adds the position in the queue, where 0 is playing, and the time until the track plays.
+// Negative positions and times are unknown and left out.
+func (e *TrackEmbed) Queued(position int, eta lavalink.Duration) *TrackEmbed {
+	if position == 0 {
+		e.field("embed.position", Messages.Text(e.style, "embed.now", nil))
+		return e
+	} else if position > 0 {
+		e.field("embed.position", Messages.Text(e.style, "embed.queue_position", Args{"Position": position}))
+	}
+	if eta >= 0 {
+		e.field("embed.eta", formatTimestamp(eta))
+	}
+	return e
+}
+
+// Adds how far the playing track is.
+func (e *TrackEmbed) Progress(position lavalink.Duration) *TrackEmbed {
+	e.field("embed.progress", formatTimestamp(position))
+	return e
+}
+
+func (e *TrackEmbed) Build() *discordgo.MessageEmbed {
+	return e.embed
+}
+
+// Embed of a track added with the interaction, showing where it ended up in the queue.
+func (b *Bot) queuedEmbed(i *discordgo.InteractionCreate, track lavalink.AudioTrack) *discordgo.MessageEmbed {
+	embed := NewTrackEmbed(b.style(i), newTrackState(track))
+	position, eta, err := b.queueSlot(i.GuildID, track.Info().Identifier)
+	if err != nil {
+		Logger.Warn("Could not find queued track: ", err)
+	}
+	if position == 0 {
+		embed.Heading(b.text(i, "embed.playing", nil))
+	} else {
+		embed.Heading(b.text(i, "embed.added", nil))
+	}
+	return embed.Queued(position, eta).Build()
+}
@@ -0,0 +1,126 @@
+package gobot
+
+import (
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+func TestTrackEmbed(t *testing.T) {
+	video := TrackState{Title: "Video", Author: "Channel", URI: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Identifier: "dQw4w9WgXcQ", Source: "youtube", LengthMs: 212000}
+	tests := []struct {
+		name     string
+		track    TrackState
+		position int
+		eta      lavalink.Duration
+		artwork  string
+		footer   string
+		fields   []string
+	}{
+		{
+			name:     "queued youtube video",
+			track:    video,
+			position: 3,
+			eta:      4 * lavalink.Minute,
+			artwork:  "https://img.youtube.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+			footer:   "YouTube",
+			fields:   []string{"Channel", "3:32", "#3", "4:00"},
+		},
+		{
+			name:     "playing stream",
+			track:    TrackState{Title: "Radio", Source: "http", IsStream: true},
+			position: 0,
+			footer:   "HTTP",
+			fields:   []string{"🔴 Live", "Playing now"},
+		},
+		{
+			name:     "unknown time",
+			track:    TrackState{Title: "File", Author: "Artist", Source: "local", LengthMs: 60000},
+			position: 2,
+			eta:      -1,
+			footer:   "Library",
+			fields:   []string{"Artist", "1:00", "#2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			embed := NewTrackEmbed(Style{}, test.track).Queued(test.position, test.eta).Build()
+			if embed.Title != test.track.Title {
+				t.Errorf("unexpected title %q", embed.Title)
+			}
+			var artwork string
+			if embed.Thumbnail != nil {
+				artwork = embed.Thumbnail.URL
+			}
+			if artwork != test.artwork {
+				t.Errorf("expected artwork %q, got %q", test.artwork, artwork)
+			}
+			if embed.Footer == nil || embed.Footer.Text != test.footer {
+				t.Errorf("expected footer %q, got %+v", test.footer, embed.Footer)
+			}
+			var fields []string
+			for _, field := range embed.Fields {
+				fields = append(fields, field.Value)
+			}
+			if len(fields) != len(test.fields) {
+				t.Fatalf("expected fields %q, got %q", test.fields, fields)
+			}
+			for n := range fields {
+				if fields[n] != test.fields[n] {
+					t.Errorf("expected fields %q, got %q", test.fields, fields)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestQueueSlot(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	stream := lavalink.NewAudioTrack(lavalink.AudioTrackInfo{Identifier: "stream", Title: "Stream", IsStream: true, SourceName: "http"})
+	playTracks(t, bot, testTrack("a", "A", 2*lavalink.Minute), testTrack("b", "B", 3*lavalink.Minute), stream, testTrack("c", "C", lavalink.Minute))
+
+	tests := []struct {
+		identifier string
+		position   int
+		eta        lavalink.Duration
+	}{
+		{identifier: "a", position: 0, eta: 0},
+		{identifier: "b", position: 1, eta: 2 * lavalink.Minute},
+		{identifier: "stream", position: 2, eta: 5 * lavalink.Minute},
+		{identifier: "c", position: 3, eta: -1},
+		{identifier: "unknown", position: -1, eta: -1},
+	}
+	for _, test := range tests {
+		position, eta, err := bot.queueSlot(testGuildID, test.identifier)
+		if err != nil || position != test.position || eta != test.eta {
+			t.Errorf("%s: expected position %d in %s, got %d in %s (%v)", test.identifier, test.position, formatTimestamp(test.eta), position, formatTimestamp(eta), err)
+		}
+	}
+
+	// The queue waits for a repeating song
+	if err := bot.setMode(testGuildID, "single"); err != nil {
+		t.Fatal(err)
+	}
+	if _, eta, _ := bot.queueSlot(testGuildID, "b"); eta != -1 {
+		t.Errorf("expected unknown time while repeating, got %s", formatTimestamp(eta))
+	}
+}
+
+func TestNowPlayingAnnouncement(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "announce_channel", "600"); err != nil {
+		t.Fatal(err)
+	}
+	track := newTrackState(testTrack("track", "Track", lavalink.Minute))
+
+	bot.announceTrack(PlayerEvent{Type: EventTrackStart, GuildID: testGuildID, Track: &track})
+
+	if got := session.messages["600"]; len(got) != 1 || got[0] != "Now playing: Track" {
+		t.Errorf("unexpected announcement: %q", got)
+	}
+	if embeds := session.embeds["600"]; len(embeds) != 1 || embeds[0].Title != "Track" {
+		t.Errorf("expected an embed of the track, got %+v", embeds)
+	}
+}
@@ -0,0 +1,113 @@
+package gobot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Causes of failed bot operations. Check them with errors.Is, userMessage turns them into replies.
+var (
+	ErrNoPlayer         = errors.New("no player manager available. Connect the bot first")
+	ErrUserNotInVoice   = errors.New("user is not in a voice channel")
+	ErrVoiceJoin        = errors.New("could not join voice channel")
+	ErrNotPlaying       = errors.New("no track is playing")
+	ErrNoMatches        = errors.New("no matches found")
+	ErrLimitExceeded    = errors.New("limit exceeded")
+	ErrUnsupportedMode  = errors.New("entered unsupported mode")
+	ErrStreamSection    = errors.New("cannot loop sections of streams")
+	ErrSectionOrder     = errors.New("section start has to be before its end")
+	ErrInvalidOptions   = errors.New("missing or unsupported command options")
+	ErrPermissionDenied = errors.New("missing permission for the command")
+)
+
+// Limit of a guild setting the request would exceed. Matches ErrLimitExceeded.
+type LimitError struct {
+	Setting string // key of the guild setting
+	Limit   int
+}
+
+func (e *LimitError) Error() string {
+	switch e.Setting {
+	case "max_track_length":
+		return fmt.Sprintf("songs are longer than the limit of %d minutes", e.Limit)
+	case "max_queue_length":
+		return fmt.Sprintf("queue is limited to %d songs", e.Limit)
+	}
+	return fmt.Sprintf("%s of %d exceeded", e.Setting, e.Limit)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Failed request to lavalink, wraps the error of disgolink or the exception of the node.
+type LavalinkError struct {
+	Op  string // what the bot asked lavalink for, e.g. load, play or seek
+	Err error
+}
+
+func (e *LavalinkError) Error() string {
+	return "lavalink " + e.Op + " failed: " + e.Err.Error()
+}
+
+func (e *LavalinkError) Unwrap() error {
+	return e.Err
+}
+
+func lavalinkError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &LavalinkError{Op: op, Err: err}
+}
+
+// Maps the error of a bot operation to the reply shown in discord.
+func userMessage(style Style, err error) string {
+	var limitErr *LimitError
+	var lavalinkErr *LavalinkError
+	switch {
+	case errors.Is(err, ErrNoPlayer):
+		return Messages.Text(style, "error.no_player", nil)
+	case errors.Is(err, ErrUserNotInVoice):
+		return Messages.Text(style, "error.user_not_in_voice", nil)
+	case errors.Is(err, ErrVoiceJoin):
+		return Messages.Text(style, "error.voice_join", nil)
+	case errors.Is(err, ErrNotPlaying):
+		return Messages.Text(style, "error.not_playing", nil)
+	case errors.Is(err, ErrNoMatches):
+		return Messages.Text(style, "error.no_matches", nil)
+	case errors.As(err, &limitErr):
+		switch limitErr.Setting {
+		case "max_track_length", "max_queue_length":
+			return Messages.Text(style, "error.limit."+limitErr.Setting, Args{"Limit": limitErr.Limit})
+		}
+		return Messages.Text(style, "error.limit", nil)
+	case errors.Is(err, ErrUnsupportedMode):
+		return Messages.Text(style, "error.unsupported_mode", nil)
+	case errors.Is(err, ErrStreamSection):
+		return Messages.Text(style, "error.stream_section", nil)
+	case errors.Is(err, ErrSectionOrder):
+		return Messages.Text(style, "error.section_order", nil)
+	case errors.As(err, &lavalinkErr):
+		if lavalinkErr.Op == "load" {
+			return Messages.Text(style, "error.load", nil)
+		}
+		return Messages.Text(style, "error.lavalink", nil)
+	}
+	return Messages.Text(style, "error.unknown", nil)
+}
+
+// Maps the error of a bot operation to the status of API responses.
+func apiStatus(err error) int {
+	var lavalinkErr *LavalinkError
+	switch {
+	case errors.Is(err, ErrNoPlayer), errors.Is(err, ErrNoMatches):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotPlaying), errors.Is(err, ErrUserNotInVoice), errors.Is(err, ErrLimitExceeded):
+		return http.StatusConflict
+	case errors.As(err, &lavalinkErr), errors.Is(err, ErrVoiceJoin):
+		return http.StatusBadGateway
+	}
+	return http.StatusBadRequest
+}
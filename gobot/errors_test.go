@@ -0,0 +1,73 @@
+package gobot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		status  int
+	}{
+		{
+			name:    "no player",
+			err:     ErrNoPlayer,
+			message: "I'm not connected. Why would you do that? 😢",
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "wrapped user not in voice",
+			err:     fmt.Errorf("%w: state not found", ErrUserNotInVoice),
+			message: "Please join a voice channel first.",
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "track length limit",
+			err:     &LimitError{Setting: "max_track_length", Limit: 10},
+			message: "Songs longer than 10 minutes are not allowed in this server.",
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "failed load",
+			err:     &LavalinkError{Op: "load", Err: errors.New("video is private")},
+			message: "Error while loading your queried track.",
+			status:  http.StatusBadGateway,
+		},
+		{
+			name:    "unknown",
+			err:     errors.New("queue position out of range"),
+			message: "An error occurred. Please try again.",
+			status:  http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := userMessage(Style{}, test.err); got != test.message {
+				t.Errorf("expected message %q, got %q", test.message, got)
+			}
+			if got := apiStatus(test.err); got != test.status {
+				t.Errorf("expected status %d, got %d", test.status, got)
+			}
+		})
+	}
+}
+
+func TestLimitErrorMatchesSentinel(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "max_queue_length", "1"); err != nil {
+		t.Fatalf("setting queue limit: %v", err)
+	}
+
+	track := testTrack("a", "A", lavalink.Minute)
+	if err := bot.play(bot.Session, testGuildID, track, track.Clone()); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected limit error, got %v", err)
+	}
+}
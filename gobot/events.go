@@ -0,0 +1,221 @@
+package gobot
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of player events. They are part of the event stream schema, so existing values must not change.
+const (
+	EventTrackStart      = "track_start"
+	EventTrackEnd        = "track_end"
+	EventTrackException  = "track_exception"
+	EventTrackStuck      = "track_stuck"
+	EventQueueChanged    = "queue_changed"
+	EventModeChanged     = "mode_changed"
+	EventPaused          = "paused"
+	EventResumed         = "resumed"
+	EventSeek            = "seek"
+	EventPlayerUpdate    = "player_update"
+	EventPlayerCreated   = "player_created"
+	EventPlayerDestroyed = "player_destroyed"
+)
+
+// Events buffered per subscriber before further events are dropped for it
+const eventBufferSize = 64
+
+// Keeps proxies from closing idle event streams
+const eventKeepAlive = 30 * time.Second
+
+// Stream tokens end up in URLs, so they expire soon. Streams opened before keep running.
+const eventTokenTTL = 30 * time.Minute
+
+// Event of a guild player. Fields not set for an event type are omitted.
+type PlayerEvent struct {
+	Type        string      `json:"type"`
+	GuildID     string      `json:"guild_id"`
+	Time        time.Time   `json:"time"`
+	Track       *TrackState `json:"track,omitempty"`
+	Reason      string      `json:"reason,omitempty"`       // track_end
+	Error       string      `json:"error,omitempty"`        // track_exception
+	ThresholdMs int64       `json:"threshold_ms,omitempty"` // track_stuck
+	QueueLength *int        `json:"queue_length,omitempty"` // queue_changed
+	Mode        string      `json:"mode,omitempty"`         // mode_changed
+	PositionMs  *int64      `json:"position_ms,omitempty"`  // seek, player_update
+}
+
+// Fans player events out to the subscribers of the event stream.
+type EventStream struct {
+	subscribers   map[chan PlayerEvent]string // maps event channels to the guild they are filtered by
+	subscribersMu sync.Mutex
+}
+
+func NewEventStream() *EventStream {
+	return &EventStream{subscribers: map[chan PlayerEvent]string{}}
+}
+
+// Never blocks since events are published while the queue is locked. Slow subscribers miss events.
+func (e *EventStream) publish(event PlayerEvent) {
+	e.subscribersMu.Lock()
+	defer e.subscribersMu.Unlock()
+	for events, guildID := range e.subscribers {
+		if guildID != "" && guildID != event.GuildID {
+			continue
+		}
+		select {
+		case events <- event:
+		default:
+			Logger.Debug("Dropping ", event.Type, " event for slow subscriber.")
+		}
+	}
+}
+
+// Subscribes to the events of a guild or of all guilds if guildID is empty.
+func (e *EventStream) subscribe(guildID string) chan PlayerEvent {
+	e.subscribersMu.Lock()
+	defer e.subscribersMu.Unlock()
+	events := make(chan PlayerEvent, eventBufferSize)
+	e.subscribers[events] = guildID
+	return events
+}
+
+func (e *EventStream) unsubscribe(events chan PlayerEvent) {
+	e.subscribersMu.Lock()
+	defer e.subscribersMu.Unlock()
+	delete(e.subscribers, events)
+}
+
+// Claims of a stream token. Tokens for a guild only stream its events.
+type EventStreamClaims struct {
+	GuildID string `json:"guild_id,omitempty"`
+	Expires int64  `json:"expires"`
+}
+
+// Creates a signed stream token. It is signed with the dashboard secret in its own scope,
+// so dashboard links are not accepted as stream tokens and the other way around.
+func (b *Bot) eventToken(guildID string) (string, EventStreamClaims, error) {
+	claims := EventStreamClaims{GuildID: guildID, Expires: time.Now().Add(eventTokenTTL).Unix()}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", claims, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(b.Dashboard.sign("events."+encoded)), claims, nil
+}
+
+func (b *Bot) verifyEventToken(token string) (EventStreamClaims, error) {
+	var claims EventStreamClaims
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, errors.New("malformed token")
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, b.Dashboard.sign("events."+payload)) {
+		return claims, errors.New("invalid token")
+	}
+
+	decodedPayload, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return claims, errors.New("malformed token")
+	}
+	if err := json.Unmarshal(decodedPayload, &claims); err != nil {
+		return claims, errors.New("malformed token")
+	}
+	if time.Now().Unix() > claims.Expires {
+		return claims, errors.New("token expired")
+	}
+	return claims, nil
+}
+
+// Issues a stream token for the guild query parameter, or for all guilds without it. Needs the admin token.
+func (b *Bot) eventTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if b.config().AdminToken == "" {
+		writeAPIError(w, http.StatusNotFound, errors.New("event stream is disabled"))
+		return
+	}
+	if !b.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeAPIError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	token, claims, err := b.eventToken(r.URL.Query().Get("guild"))
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPI(w, http.StatusOK, map[string]interface{}{"token": token, "expires": claims.Expires})
+}
+
+// Streams player events as server-sent events. The guild query parameter filters by guild.
+// Authenticates with the admin token in the Authorization header, or with a stream token as token
+// query parameter, since EventSource can't set headers.
+func (b *Bot) eventStream(w http.ResponseWriter, r *http.Request) {
+	if b.config().AdminToken == "" {
+		writeAPIError(w, http.StatusNotFound, errors.New("event stream is disabled"))
+		return
+	}
+	guildID := r.URL.Query().Get("guild")
+	if !b.authorized(r) {
+		claims, err := b.verifyEventToken(r.URL.Query().Get("token"))
+		if err == nil && claims.GuildID != "" {
+			if guildID != "" && guildID != claims.GuildID {
+				err = errors.New("token is for another guild")
+			}
+			guildID = claims.GuildID
+		}
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	events := b.Events.subscribe(guildID)
+	defer b.Events.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				Logger.Warn("Failed to encode player event: ", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,82 @@
+package gobot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEventStreamAuthorization(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	bot.Config.AdminToken = "secret"
+
+	allGuilds, _, err := bot.eventToken("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	guildToken, _, err := bot.eventToken(testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dashboardLink, err := bot.Dashboard.Link("http://localhost", testGuildID, testUserID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, dashboardToken, _ := strings.Cut(dashboardLink, "token=")
+
+	tests := []struct {
+		name   string
+		query  string
+		header string
+		status int
+	}{
+		{name: "admin token header", header: "Bearer secret", status: http.StatusOK},
+		{name: "admin token in URL", query: "token=secret", status: http.StatusUnauthorized},
+		{name: "wrong admin token", header: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "stream token", query: "token=" + allGuilds, status: http.StatusOK},
+		{name: "guild stream token", query: "guild=" + testGuildID + "&token=" + guildToken, status: http.StatusOK},
+		{name: "stream token of another guild", query: "guild=1&token=" + guildToken, status: http.StatusUnauthorized},
+		{name: "dashboard link", query: "token=" + dashboardToken, status: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Canceled right away, so accepted streams end after sending the headers
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			request := httptest.NewRequest(http.MethodGet, "/events?"+test.query, nil).WithContext(ctx)
+			if test.header != "" {
+				request.Header.Set("Authorization", test.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			bot.eventStream(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("expected status %d, got %d: %s", test.status, recorder.Code, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestEventTokenHandler(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	bot.Config.AdminToken = "secret"
+
+	request := httptest.NewRequest(http.MethodPost, "/events/token?token=secret", nil)
+	recorder := httptest.NewRecorder()
+	bot.eventTokenHandler(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected the admin token in the URL to be rejected, got %d", recorder.Code)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/events/token?guild="+testGuildID, nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	recorder = httptest.NewRecorder()
+	bot.eventTokenHandler(recorder, request)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"token"`) {
+		t.Errorf("expected a token, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
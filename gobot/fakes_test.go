@@ -0,0 +1,343 @@
+package gobot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+const (
+	testGuildID   = "100"
+	testChannelID = "200"
+	testUserID    = "300"
+	testBotID     = "400"
+)
+
+// Records responses and keeps the voice states of users in memory.
+type fakeSession struct {
+	mu          sync.Mutex
+	responses   []*discordgo.InteractionResponse
+	followups   []*discordgo.WebhookParams
+	messages    map[string][]string                  // maps channels to sent messages
+	embeds      map[string][]*discordgo.MessageEmbed // maps channels to the embeds of sent messages
+	voiceStates map[string]*discordgo.VoiceState     // maps guild and user ID to voice states
+	status      string
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{
+		messages:    map[string][]string{},
+		embeds:      map[string][]*discordgo.MessageEmbed{},
+		voiceStates: map[string]*discordgo.VoiceState{},
+	}
+}
+
+func (s *fakeSession) InteractionRespond(_ *discordgo.Interaction, resp *discordgo.InteractionResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func (s *fakeSession) FollowupMessageCreate(_ *discordgo.Interaction, _ bool, data *discordgo.WebhookParams) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.followups = append(s.followups, data)
+	return &discordgo.Message{Content: data.Content}, nil
+}
+
+func (s *fakeSession) ChannelMessageSend(channelID string, content string) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[channelID] = append(s.messages[channelID], content)
+	return &discordgo.Message{ChannelID: channelID, Content: content}, nil
+}
+
+func (s *fakeSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[channelID] = append(s.messages[channelID], data.Content)
+	s.embeds[channelID] = append(s.embeds[channelID], data.Embeds...)
+	return &discordgo.Message{ChannelID: channelID, Content: data.Content, Embeds: data.Embeds}, nil
+}
+
+// Joins or leaves like the gateway would, an empty channel leaves.
+func (s *fakeSession) ChannelVoiceJoinManual(guildID string, channelID string, _ bool, _ bool) error {
+	if channelID == "" {
+		s.leaveVoice(guildID, testBotID)
+		return nil
+	}
+	s.joinVoice(guildID, channelID, testBotID)
+	return nil
+}
+
+func (s *fakeSession) UpdateGameStatus(_ int, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = name
+	return nil
+}
+
+func (s *fakeSession) HeartbeatLatency() time.Duration {
+	return 42 * time.Millisecond
+}
+
+func (s *fakeSession) UserID() string {
+	return testBotID
+}
+
+func (s *fakeSession) VoiceState(guildID string, userID string) (*discordgo.VoiceState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.voiceStates[guildID+"/"+userID]; ok {
+		return state, nil
+	}
+	return nil, discordgo.ErrStateNotFound
+}
+
+func (s *fakeSession) Connected() bool {
+	return true
+}
+
+func (s *fakeSession) joinVoice(guildID string, channelID string, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voiceStates[guildID+"/"+userID] = &discordgo.VoiceState{GuildID: guildID, ChannelID: channelID, UserID: userID}
+}
+
+func (s *fakeSession) leaveVoice(guildID string, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.voiceStates, guildID+"/"+userID)
+}
+
+// Content of the last interaction response.
+func (s *fakeSession) lastResponse(t *testing.T) string {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.responses) == 0 {
+		t.Fatal("no interaction response was sent")
+	}
+	return s.responses[len(s.responses)-1].Data.Content
+}
+
+func (s *fakeSession) lastFollowup(t *testing.T) *discordgo.WebhookParams {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.followups) == 0 {
+		t.Fatal("no follow up message was sent")
+	}
+	return s.followups[len(s.followups)-1]
+}
+
+// Resolves queries from a fixed map and creates fake players.
+type fakeLavalink struct {
+	tracks     map[string][]lavalink.AudioTrack // maps queries to the tracks they load
+	loadErrors map[string]error                 // maps queries to errors of the REST API
+	players    map[string]*fakePlayer
+}
+
+func newFakeLavalink() *fakeLavalink {
+	return &fakeLavalink{
+		tracks:     map[string][]lavalink.AudioTrack{},
+		loadErrors: map[string]error{},
+		players:    map[string]*fakePlayer{},
+	}
+}
+
+func (l *fakeLavalink) Player(guildID string) (AudioPlayer, error) {
+	if player, ok := l.players[guildID]; ok {
+		return player, nil
+	}
+	player := &fakePlayer{volume: 100}
+	l.players[guildID] = player
+	return player, nil
+}
+
+// Single tracks are loaded with the query as encoded track.
+func (l *fakeLavalink) LoadItem(_ context.Context, identifier string) (*lavalink.LoadResult, error) {
+	if err := l.loadErrors[identifier]; err != nil {
+		return nil, err
+	}
+	if tracks := l.tracks[identifier]; len(tracks) == 1 {
+		return &lavalink.LoadResult{
+			LoadType: lavalink.LoadTypeTrackLoaded,
+			Tracks:   []lavalink.RestAudioTrack{{Track: identifier, Info: tracks[0].Info()}},
+		}, nil
+	}
+	return &lavalink.LoadResult{LoadType: lavalink.LoadTypeNoMatches}, nil
+}
+
+// Single tracks are loaded as track, several tracks as search result.
+func (l *fakeLavalink) LoadItemHandler(_ context.Context, identifier string, handler lavalink.AudioLoadResultHandler) error {
+	switch tracks := l.tracks[identifier]; len(tracks) {
+	case 0:
+		handler.NoMatches()
+	case 1:
+		handler.TrackLoaded(tracks[0])
+	default:
+		handler.SearchResultLoaded(tracks)
+	}
+	return nil
+}
+
+func (l *fakeLavalink) DecodeTrack(track string) (lavalink.AudioTrack, error) {
+	if tracks := l.tracks[track]; len(tracks) == 1 {
+		return tracks[0].Clone(), nil
+	}
+	return nil, errors.New("unknown encoded track " + track)
+}
+
+// Plays tracks instantly. The position only changes by seeking.
+type fakePlayer struct {
+	track     lavalink.AudioTrack
+	position  lavalink.Duration
+	paused    bool
+	volume    int
+	destroyed bool
+	listeners []any
+}
+
+func (p *fakePlayer) Play(track lavalink.AudioTrack) error {
+	p.track = track
+	p.position = track.Info().Position
+	return nil
+}
+
+func (p *fakePlayer) Stop() error {
+	p.track = nil
+	p.position = 0
+	return nil
+}
+
+func (p *fakePlayer) Destroy() error {
+	p.destroyed = true
+	return nil
+}
+
+func (p *fakePlayer) Pause(paused bool) error {
+	p.paused = paused
+	return nil
+}
+
+func (p *fakePlayer) Paused() bool {
+	return p.paused
+}
+
+func (p *fakePlayer) Seek(position lavalink.Duration) error {
+	if p.track == nil {
+		return errors.New("no track is playing")
+	}
+	p.position = position
+	return nil
+}
+
+func (p *fakePlayer) Position() lavalink.Duration {
+	return p.position
+}
+
+func (p *fakePlayer) PlayingTrack() lavalink.AudioTrack {
+	return p.track
+}
+
+func (p *fakePlayer) Volume() int {
+	return p.volume
+}
+
+func (p *fakePlayer) SetVolume(volume int) error {
+	p.volume = volume
+	return nil
+}
+
+// Fake players are not connected to a node.
+func (p *fakePlayer) Node() lavalink.Node {
+	return nil
+}
+
+func (p *fakePlayer) ChangeNode(_ lavalink.Node) {}
+
+func (p *fakePlayer) AddListener(listener any) {
+	p.listeners = append(p.listeners, listener)
+}
+
+// Bot using fakes for discord and lavalink, with the command user in a voice channel.
+func newTestBot(t *testing.T) (*Bot, *fakeSession, *fakeLavalink) {
+	t.Helper()
+	settings, err := NewGuildSettingsStore(filepath.Join(t.TempDir(), "guilds.json"))
+	if err != nil {
+		t.Fatalf("creating guild settings: %v", err)
+	}
+
+	dashboard, err := NewDashboard()
+	if err != nil {
+		t.Fatalf("creating dashboard: %v", err)
+	}
+
+	session := newFakeSession()
+	session.joinVoice(testGuildID, testChannelID, testUserID)
+	link := newFakeLavalink()
+	bot := &Bot{
+		Config:         Configuration{},
+		Session:        session,
+		Lavalink:       link,
+		PlayerManagers: map[string]*PlayerManager{},
+		TrackMap:       map[string]map[string]lavalink.AudioTrack{},
+		Settings:       settings,
+		Dashboard:      dashboard,
+		Events:         NewEventStream(),
+		Bus:            NewEventBus(),
+	}
+	return bot, session, link
+}
+
+func testTrack(identifier string, title string, length lavalink.Duration) lavalink.AudioTrack {
+	uri := "https://example.com/" + identifier
+	return lavalink.NewAudioTrack(lavalink.AudioTrackInfo{
+		Identifier: identifier,
+		Author:     "Author",
+		Length:     length,
+		Title:      title,
+		URI:        &uri,
+		SourceName: "http",
+	})
+}
+
+// Slash command interaction of the test user in the test guild.
+func commandInteraction(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "1",
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: testGuildID,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: testUserID, Username: "tester"}},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:    name,
+				Options: options,
+			},
+		},
+	}
+}
+
+func stringOption(name string, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionString,
+		Value: value,
+	}
+}
+
+func subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}
+}
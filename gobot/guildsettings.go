@@ -0,0 +1,235 @@
+package gobot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Settings of a single guild. The json tags are the keys used by the /settings command.
+// Defaults and validation use the same tags as the configuration.
+type GuildSettings struct {
+	Volume          int    `json:"volume" default:"100" min:"0" max:"1000" doc:"Player volume when the bot joins."`
+	DJRole          string `json:"dj_role" format:"snowflake" doc:"Role required to control playback. Empty allows everyone."`
+	AnnounceChannel string `json:"announce_channel" format:"snowflake" doc:"Channel to announce playing songs in. Empty disables announcements."`
+	AnnounceQueued  bool   `json:"announce_queued" default:"false" doc:"Also announce songs added to the queue in the announce channel."`
+	SearchSource    string `json:"search_source" default:"ytsearch" enum:"ytsearch,ytmsearch,scsearch" doc:"Source queries are searched on."`
+	Autoplay        bool   `json:"autoplay" default:"false" doc:"Play related songs once the queue runs out."`
+	IdleTimeout     int    `json:"idle_timeout" default:"0" min:"0" max:"1440" doc:"Minutes to stay in voice without playing. 0 stays forever."`
+	MaxQueueLength  int    `json:"max_queue_length" default:"0" min:"0" max:"10000" doc:"Maximum number of queued songs. 0 is unlimited."`
+	MaxTrackLength  int    `json:"max_track_length" default:"0" min:"0" max:"1440" doc:"Maximum song length in minutes. 0 is unlimited."`
+	Language        string `json:"language" default:"auto" enum:"auto,en,ja" doc:"Language of the responses. auto answers every user in their discord language."`
+	Theme           string `json:"theme" default:"playful" format:"theme" doc:"Personality of the responses: playful, neutral or a custom theme."`
+	Visibility      string `json:"visibility" default:"private" enum:"private,public" doc:"Who sees responses to commands: private shows them only to the user, public to the channel."`
+	PublicCommands  string `json:"public_commands" format:"commands" doc:"Comma separated commands that always respond publicly, e.g. play,skip."`
+	PrivateCommands string `json:"private_commands" format:"commands" doc:"Comma separated commands that always respond privately."`
+}
+
+// Guild settings persisted in a local JSON file.
+type GuildSettingsStore struct {
+	File     string
+	Guilds   map[string]GuildSettings
+	GuildsMu sync.Mutex
+}
+
+func defaultGuildSettings() GuildSettings {
+	settings := GuildSettings{}
+	values := reflect.ValueOf(&settings).Elem()
+	for i := 0; i < values.NumField(); i++ {
+		if value, ok := values.Type().Field(i).Tag.Lookup("default"); ok {
+			if err := setConfigField(values.Field(i), value); err != nil {
+				Logger.Panic("Invalid default guild setting: ", err)
+			}
+		}
+	}
+	return settings
+}
+
+// Loads the stored guild settings. A missing file starts an empty store.
+func NewGuildSettingsStore(file string) (*GuildSettingsStore, error) {
+	guilds, err := readGuildSettings(file)
+	if err != nil {
+		return nil, err
+	}
+	return &GuildSettingsStore{File: file, Guilds: guilds}, nil
+}
+
+// Reads the stored guild settings again, e.g. after the file was edited or imported.
+// The current settings are kept if the file is invalid.
+func (g *GuildSettingsStore) Reload() error {
+	guilds, err := readGuildSettings(g.File)
+	if err != nil {
+		return err
+	}
+
+	g.GuildsMu.Lock()
+	defer g.GuildsMu.Unlock()
+	g.Guilds = guilds
+	return nil
+}
+
+func readGuildSettings(file string) (map[string]GuildSettings, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		Logger.Info("Guild settings file " + file + " not found. Starting with defaults.")
+		return map[string]GuildSettings{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Decode every guild on top of the defaults so settings added later get their default
+	var raws map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("could not parse guild settings %s: %w", file, err)
+	}
+	guilds := map[string]GuildSettings{}
+	for guildID, raw := range raws {
+		settings, err := decodeGuildSettings(raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse guild settings of %s: %w", guildID, err)
+		}
+		guilds[guildID] = settings
+	}
+	return guilds, nil
+}
+
+func decodeGuildSettings(raw json.RawMessage) (GuildSettings, error) {
+	settings := defaultGuildSettings()
+	err := json.Unmarshal(raw, &settings)
+	return settings, err
+}
+
+// Returns the settings of the guild or the defaults if nothing was set.
+func (g *GuildSettingsStore) Get(guildID string) GuildSettings {
+	g.GuildsMu.Lock()
+	defer g.GuildsMu.Unlock()
+	if settings, ok := g.Guilds[guildID]; ok {
+		return settings
+	}
+	return defaultGuildSettings()
+}
+
+// Validates and stores a single setting by its key.
+func (g *GuildSettingsStore) Set(guildID string, key string, value string) error {
+	g.GuildsMu.Lock()
+	defer g.GuildsMu.Unlock()
+
+	settings, ok := g.Guilds[guildID]
+	if !ok {
+		settings = defaultGuildSettings()
+	}
+
+	field, ok := guildSettingField(key)
+	if !ok {
+		return errors.New("unknown setting " + key)
+	}
+
+	// Accept role and channel mentions for IDs
+	if field.Tag.Get("format") == "snowflake" {
+		value = strings.Trim(strings.TrimSpace(value), "<@&#>")
+	}
+	if field.Tag.Get("format") == "commands" {
+		value = strings.Join(commandList(value), ",")
+	}
+
+	values := reflect.ValueOf(&settings).Elem()
+	if err := setConfigField(values.FieldByName(field.Name), value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if problems := validateFields(values, guildSettingLabel); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	g.Guilds[guildID] = settings
+	return g.save()
+}
+
+// Resets a single setting to its default or all settings if the key is empty.
+func (g *GuildSettingsStore) Reset(guildID string, key string) error {
+	g.GuildsMu.Lock()
+	defer g.GuildsMu.Unlock()
+
+	settings, ok := g.Guilds[guildID]
+	if !ok {
+		return nil
+	}
+
+	if key == "" {
+		delete(g.Guilds, guildID)
+		return g.save()
+	}
+
+	field, ok := guildSettingField(key)
+	if !ok {
+		return errors.New("unknown setting " + key)
+	}
+	defaults := reflect.ValueOf(defaultGuildSettings())
+	reflect.ValueOf(&settings).Elem().FieldByName(field.Name).Set(defaults.FieldByName(field.Name))
+
+	g.Guilds[guildID] = settings
+	return g.save()
+}
+
+// Writes the settings to a temporary file first so a crash does not corrupt the store.
+func (g *GuildSettingsStore) save() error {
+	data, err := json.MarshalIndent(g.Guilds, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(g.File), filepath.Base(g.File)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), g.File)
+}
+
+// Keys and descriptions of all guild settings.
+func GuildSettingKeys() map[string]string {
+	keys := map[string]string{}
+	settingsType := reflect.TypeOf(GuildSettings{})
+	for i := 0; i < settingsType.NumField(); i++ {
+		field := settingsType.Field(i)
+		keys[field.Tag.Get("json")] = field.Tag.Get("doc")
+	}
+	return keys
+}
+
+// Command names of a comma separated setting like public_commands.
+func commandList(value string) []string {
+	var commands []string
+	for _, command := range strings.Split(value, ",") {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+func guildSettingField(key string) (reflect.StructField, bool) {
+	settingsType := reflect.TypeOf(GuildSettings{})
+	for i := 0; i < settingsType.NumField(); i++ {
+		if field := settingsType.Field(i); field.Tag.Get("json") == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func guildSettingLabel(field reflect.StructField) string {
+	return field.Tag.Get("json")
+}
@@ -0,0 +1,115 @@
+package gobot
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// State of the bot reported by the health endpoints.
+type HealthStatus struct {
+	Status   string       `json:"status"`
+	Discord  DiscordState `json:"discord"`
+	Lavalink []NodeState  `json:"lavalink"`
+}
+
+type DiscordState struct {
+	Connected bool  `json:"connected"`
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+type NodeState struct {
+	Name           string  `json:"name"`
+	Status         string  `json:"status"`
+	Players        int     `json:"players"`
+	PlayingPlayers int     `json:"playing_players"`
+	UptimeMs       int64   `json:"uptime_ms"`
+	MemoryUsed     int     `json:"memory_used"`
+	SystemLoad     float64 `json:"system_load"`
+	LavalinkLoad   float64 `json:"lavalink_load"`
+}
+
+// Serves the operational HTTP endpoints. Failing to listen is logged since the bot works without them.
+func (b *Bot) serveHTTP(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", b.healthz)
+	mux.HandleFunc("/readyz", b.readyz)
+	mux.HandleFunc("/api/guilds", b.adminAPI)
+	mux.HandleFunc("/api/guilds/", b.adminAPI)
+	mux.HandleFunc("/events", b.eventStream)
+	mux.HandleFunc("/events/token", b.eventTokenHandler)
+	b.handleDashboard(mux)
+
+	Logger.Info("Serving HTTP endpoints on ", address)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		Logger.Warn("HTTP server stopped: ", err)
+	}
+}
+
+// Alive as long as the discord gateway is connected. Discordgo reconnects on its own, so lavalink outages are left to /readyz.
+func (b *Bot) healthz(w http.ResponseWriter, r *http.Request) {
+	health := b.health()
+	writeHealth(w, health, health.Discord.Connected)
+}
+
+// Ready to play music if the discord gateway and at least one lavalink node are connected.
+func (b *Bot) readyz(w http.ResponseWriter, r *http.Request) {
+	health := b.health()
+	ready := health.Discord.Connected
+	if ready {
+		ready = false
+		for _, node := range health.Lavalink {
+			if node.Status == string(lavalink.Connected) {
+				ready = true
+			}
+		}
+	}
+	writeHealth(w, health, ready)
+}
+
+func (b *Bot) health() HealthStatus {
+	health := HealthStatus{
+		Discord: DiscordState{
+			Connected: b.Session.Connected(),
+			LatencyMs: b.Session.HeartbeatLatency().Milliseconds(),
+		},
+		Lavalink: []NodeState{},
+	}
+
+	for _, node := range b.Link.Nodes() {
+		status, stats := b.Nodes.State(node)
+		state := NodeState{
+			Name:   node.Name(),
+			Status: string(status),
+		}
+		if stats != nil {
+			state.Players = stats.Players
+			state.PlayingPlayers = stats.PlayingPlayers
+			state.UptimeMs = stats.Uptime.Milliseconds()
+			state.MemoryUsed = stats.Memory.Used
+			state.SystemLoad = stats.CPU.SystemLoad
+			state.LavalinkLoad = stats.CPU.LavalinkLoad
+		}
+		health.Lavalink = append(health.Lavalink, state)
+	}
+
+	return health
+}
+
+func writeHealth(w http.ResponseWriter, health HealthStatus, ok bool) {
+	health.Status = "ok"
+	status := http.StatusOK
+	if !ok {
+		health.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		Logger.Warn("Failed to write health status: ", err)
+	}
+}
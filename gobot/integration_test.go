@@ -0,0 +1,364 @@
+package gobot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgolink/dgolink"
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Bot wired like StartBot and connected to a fake lavalink node. Discord is replaced by the fake session.
+// Player events of the bus are forwarded to the returned channel.
+func newIntegrationBot(t *testing.T) (*Bot, *fakeSession, *fakeLavalinkServer, chan PlayerEvent) {
+	t.Helper()
+	server := newFakeLavalinkServer(t)
+
+	conf, err := defaultConfig()
+	if err != nil {
+		t.Fatalf("loading defaults: %v", err)
+	}
+	conf.GuildSettingsFile = filepath.Join(t.TempDir(), "guilds.json")
+	server.configure(t, &conf)
+
+	session := newFakeSession()
+	session.joinVoice(testGuildID, testChannelID, testUserID)
+	link := &dgolink.Link{Lavalink: lavalink.New(lavalink.WithUserIDString(testBotID), lavalink.WithLogger(Logger))}
+	bot, err := newBot(conf, session, link)
+	if err != nil {
+		t.Fatalf("creating bot: %v", err)
+	}
+
+	events := make(chan PlayerEvent, 256)
+	bot.Bus.Subscribe("test", func(event interface{}) {
+		if playerEvent, ok := event.(PlayerEvent); ok {
+			events <- playerEvent
+		}
+	})
+
+	if _, err := bot.addNode(conf); err != nil {
+		t.Fatalf("connecting to fake lavalink node: %v", err)
+	}
+	// Closed before the server so the node does not reconnect
+	t.Cleanup(link.Close)
+	server.waitConnection(t)
+	if err := bot.Link.BestNode().ConfigureResuming(conf.ResumeKey, conf.ResumeTimeOut); err != nil {
+		t.Fatalf("configuring resuming: %v", err)
+	}
+
+	return bot, session, server, events
+}
+
+// Waits for the next player event of the type, skipping other events.
+func waitEvent(t *testing.T, events chan PlayerEvent, eventType string) PlayerEvent {
+	t.Helper()
+	timeout := time.After(fakeLavalinkTimeout)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no %s event was published", eventType)
+		}
+	}
+}
+
+// Waits until the bot started the track and received its position, so the player state is settled.
+func waitTrackStart(t *testing.T, server *fakeLavalinkServer, events chan PlayerEvent, identifier string) {
+	t.Helper()
+	op := server.waitOp(t, lavalink.OpTypePlay)
+	track, err := lavalink.DecodeString(op.Track, nil)
+	if err != nil {
+		t.Fatalf("decoding played track: %v", err)
+	}
+	if track.Info().Identifier != identifier || op.GuildID != testGuildID {
+		t.Fatalf("expected %s to be played in guild %s, got %s in guild %s", identifier, testGuildID, track.Info().Identifier, op.GuildID)
+	}
+	if event := waitEvent(t, events, EventTrackStart); event.Track == nil || event.Track.Identifier != identifier {
+		t.Fatalf("expected track_start of %s, got %+v", identifier, event.Track)
+	}
+	waitEvent(t, events, EventPlayerUpdate)
+}
+
+// Plays the tracks with the play command. The first track starts, the others are queued.
+func playURLs(t *testing.T, bot *Bot, server *fakeLavalinkServer, events chan PlayerEvent, tracks ...lavalink.AudioTrack) {
+	t.Helper()
+	for index, track := range tracks {
+		server.addResult(t, *track.Info().URI, lavalink.LoadTypeTrackLoaded, track)
+		bot.handleInteraction(commandInteraction("play", stringOption("query", *track.Info().URI)))
+		if index == 0 {
+			waitTrackStart(t, server, events, track.Info().Identifier)
+		} else {
+			waitEvent(t, events, EventQueueChanged)
+		}
+	}
+}
+
+func integrationTrack(identifier string) lavalink.AudioTrack {
+	uri := "https://www.youtube.com/watch?v=" + identifier
+	return lavalink.NewAudioTrack(lavalink.AudioTrackInfo{
+		Identifier: identifier,
+		Author:     "Author",
+		Length:     3 * lavalink.Minute,
+		Title:      "Track " + identifier,
+		URI:        &uri,
+		SourceName: "youtube",
+	})
+}
+
+func TestIntegrationPlay(t *testing.T) {
+	bot, session, server, events := newIntegrationBot(t)
+
+	playURLs(t, bot, server, events, integrationTrack("a"), integrationTrack("b"))
+
+	if got := session.lastFollowup(t).Content; !strings.HasPrefix(got, "Adding the song to queue") {
+		t.Errorf("unexpected follow up: %q", got)
+	}
+	if state, _ := session.VoiceState(testGuildID, testBotID); state == nil || state.ChannelID != testChannelID {
+		t.Errorf("bot did not join the voice channel of the user: %+v", state)
+	}
+	if tracks, err := bot.getTracks(testGuildID); err != nil || len(tracks) != 1 || tracks[0].Info().Identifier != "b" {
+		t.Errorf("expected b to be queued, got %v (%v)", tracks, err)
+	}
+}
+
+func TestIntegrationPlaySearch(t *testing.T) {
+	bot, session, server, _ := newIntegrationBot(t)
+	var results []lavalink.AudioTrack
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		results = append(results, integrationTrack(id))
+	}
+	server.addResult(t, "ytsearch:query", lavalink.LoadTypeSearchResult, results...)
+	server.addLoadFailure("https://www.youtube.com/watch?v=private", "This video is private")
+
+	bot.handleInteraction(commandInteraction("play", stringOption("query", "query")))
+	if got := session.lastFollowup(t).Content; got != "Please choose a song from the menu." {
+		t.Errorf("unexpected follow up for search: %q", got)
+	}
+	if choices := bot.TrackMap[testUserID]; len(choices) != 5 {
+		t.Errorf("expected 5 choices, got %d", len(choices))
+	}
+
+	bot.handleInteraction(commandInteraction("play", stringOption("query", "https://www.youtube.com/watch?v=private")))
+	if got := session.lastFollowup(t).Content; got != "Error while loading your queried track." {
+		t.Errorf("unexpected follow up for failed load: %q", got)
+	}
+
+	bot.handleInteraction(commandInteraction("play", stringOption("query", "nothing")))
+	if got := session.lastFollowup(t).Content; got != "No matches found for your query." {
+		t.Errorf("unexpected follow up without matches: %q", got)
+	}
+}
+
+func TestIntegrationRepeatModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		next []string // identifiers of the tracks started by the next track ends
+	}{
+		{mode: "off", next: []string{"b"}},
+		{mode: "single", next: []string{"a", "a"}},
+		{mode: "all", next: []string{"b", "a", "b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			bot, session, server, events := newIntegrationBot(t)
+			playURLs(t, bot, server, events, integrationTrack("a"), integrationTrack("b"))
+
+			bot.handleInteraction(commandInteraction("set", subcommand(test.mode)))
+			if got := session.lastResponse(t); got != "Set mode to: "+test.mode {
+				t.Fatalf("unexpected response: %q", got)
+			}
+			waitEvent(t, events, EventModeChanged)
+
+			for _, identifier := range test.next {
+				server.finishTrack(t, testGuildID)
+				if event := waitEvent(t, events, EventTrackEnd); event.Reason != "finished" {
+					t.Errorf("expected finished track end, got %q", event.Reason)
+				}
+				waitTrackStart(t, server, events, identifier)
+			}
+		})
+	}
+}
+
+func TestIntegrationTrackErrors(t *testing.T) {
+	bot, _, server, events := newIntegrationBot(t)
+	playURLs(t, bot, server, events, integrationTrack("a"), integrationTrack("b"))
+
+	server.stickTrack(t, testGuildID, 10*lavalink.Second)
+	if event := waitEvent(t, events, EventTrackStuck); event.Track == nil || event.Track.Identifier != "a" {
+		t.Errorf("expected a to be stuck, got %+v", event.Track)
+	}
+
+	// Failed tracks are skipped
+	server.failTrack(t, testGuildID, "Something broke when playing the track.")
+	if event := waitEvent(t, events, EventTrackException); event.Error != "Something broke when playing the track." {
+		t.Errorf("unexpected track exception: %q", event.Error)
+	}
+	if event := waitEvent(t, events, EventTrackEnd); event.Reason != "load_failed" {
+		t.Errorf("expected load_failed track end, got %q", event.Reason)
+	}
+	waitTrackStart(t, server, events, "b")
+}
+
+func TestIntegrationSkipSeekLeave(t *testing.T) {
+	bot, session, server, events := newIntegrationBot(t)
+	playURLs(t, bot, server, events, integrationTrack("a"), integrationTrack("b"))
+
+	bot.handleInteraction(commandInteraction("skip", subcommand("single")))
+	if event := waitEvent(t, events, EventTrackEnd); event.Reason != "replaced" {
+		t.Errorf("expected replaced track end, got %q", event.Reason)
+	}
+	waitTrackStart(t, server, events, "b")
+
+	bot.handleInteraction(commandInteraction("seek", subcommand("absolute", stringOption("position", "1:30"))))
+	if op := server.waitOp(t, lavalink.OpTypeSeek); op.Position != 90000 {
+		t.Errorf("expected seek to 90000 ms, got %d", op.Position)
+	}
+	if event := waitEvent(t, events, EventPlayerUpdate); event.PositionMs == nil || *event.PositionMs != 90000 {
+		t.Errorf("expected player update at 90000 ms, got %v", event.PositionMs)
+	}
+
+	bot.handleInteraction(commandInteraction("leave"))
+	server.waitOp(t, lavalink.OpTypeStop)
+	server.waitOp(t, lavalink.OpTypeDestroy)
+	waitEvent(t, events, EventPlayerDestroyed)
+	if got := session.lastResponse(t); got != "行ってきます、ご主人様" {
+		t.Errorf("unexpected response: %q", got)
+	}
+	if _, ok := bot.PlayerManagers[testGuildID]; ok {
+		t.Error("player manager was not removed")
+	}
+}
+
+func TestIntegrationSectionLoop(t *testing.T) {
+	bot, _, server, events := newIntegrationBot(t)
+	playURLs(t, bot, server, events, integrationTrack("a"))
+
+	bot.handleInteraction(commandInteraction("loop", subcommand("section", stringOption("start", "0:10"), stringOption("end", "0:20"))))
+	if op := server.waitOp(t, lavalink.OpTypeSeek); op.Position != 10000 {
+		t.Errorf("expected seek to the section start, got %d ms", op.Position)
+	}
+	waitEvent(t, events, EventPlayerUpdate)
+
+	server.sendPlayerUpdate(testGuildID, 25*lavalink.Second)
+	if op := server.waitOp(t, lavalink.OpTypeSeek); op.Position != 10000 {
+		t.Errorf("expected the loop to seek back to the section start, got %d ms", op.Position)
+	}
+
+	bot.handleInteraction(commandInteraction("set", subcommand("off")))
+	if event := waitEvent(t, events, EventModeChanged); event.Mode != "off" {
+		t.Errorf("expected the section loop to stop, got mode %q", event.Mode)
+	}
+}
+
+func TestIntegrationAutoplay(t *testing.T) {
+	bot, session, server, events := newIntegrationBot(t)
+	playURLs(t, bot, server, events, integrationTrack("a"))
+	server.addResult(t, "https://www.youtube.com/watch?v=a&list=RDa", lavalink.LoadTypePlaylistLoaded,
+		integrationTrack("a"), integrationTrack("c"))
+
+	bot.handleInteraction(commandInteraction("autoplay", subcommand("on")))
+	if got := session.lastResponse(t); got != "Set autoplay to: on" {
+		t.Fatalf("unexpected response: %q", got)
+	}
+
+	server.finishTrack(t, testGuildID)
+	waitTrackStart(t, server, events, "c")
+}
+
+func TestIntegrationNodeReconnect(t *testing.T) {
+	bot, _, server, events := newIntegrationBot(t)
+
+	server.dropConnection()
+	server.waitConnection(t)
+
+	// The node only reports the connection once the reconnect completed
+	deadline := time.Now().Add(fakeLavalinkTimeout)
+	for {
+		recorder := httptest.NewRecorder()
+		bot.readyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if recorder.Code == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("bot did not become ready after reconnecting: %s", recorder.Body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	playURLs(t, bot, server, events, integrationTrack("a"))
+}
+
+// Writes the configuration to a file to reload it from.
+func writeConfig(t *testing.T, conf Configuration) string {
+	t.Helper()
+	data, err := json.Marshal(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configFile
+}
+
+// A reload that only moves the node keeps its name, so the old node is closed after the new one connected.
+func TestIntegrationReloadNode(t *testing.T) {
+	bot, _, _, events := newIntegrationBot(t)
+	replacement := newFakeLavalinkServer(t)
+
+	conf := bot.config()
+	conf.DiscordToken = "token"
+	replacement.configure(t, &conf)
+
+	bot.reloadConfig(writeConfig(t, conf))
+	replacement.waitConnection(t)
+	if got := bot.config().LavalinkPort; got != conf.LavalinkPort {
+		t.Fatalf("expected the new node port %s to be applied, got %s", conf.LavalinkPort, got)
+	}
+
+	recorder := httptest.NewRecorder()
+	bot.readyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the bot to be ready on the new node, got %d: %s", recorder.Code, recorder.Body)
+	}
+	up := collectDesc(t, nodeCollector{link: bot.Link, nodes: bot.Nodes}, nodeUpDesc)
+	if len(up) != 1 || up[conf.LavalinkNode] != 1 {
+		t.Errorf("expected the node to be up, got %v", up)
+	}
+
+	playURLs(t, bot, replacement, events, integrationTrack("a"))
+}
+
+// Changes applied along with a node that fails to connect are kept, while the node settings are rolled back.
+func TestIntegrationReloadNodeFailure(t *testing.T) {
+	bot, _, server, events := newIntegrationBot(t)
+
+	conf := bot.config()
+	conf.DiscordToken = "token"
+	conf.LavalinkPW = "wrong"
+	conf.AdminToken = "secret"
+
+	bot.reloadConfig(writeConfig(t, conf))
+	if got := bot.config(); got.LavalinkPW != fakeLavalinkPassword || got.AdminToken != "secret" {
+		t.Errorf("expected only the node settings to be rolled back, got password %q and admin token %q", got.LavalinkPW, got.AdminToken)
+	}
+
+	recorder := httptest.NewRecorder()
+	bot.readyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the bot to stay ready on the current node, got %d: %s", recorder.Code, recorder.Body)
+	}
+	playURLs(t, bot, server, events, integrationTrack("a"))
+}
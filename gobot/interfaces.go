@@ -0,0 +1,186 @@
+package gobot
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/dgolink"
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Discord session operations used by the bot, so tests can replace the session with a fake.
+type Session interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams) (*discordgo.Message, error)
+	ChannelMessageSend(channelID string, content string) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error)
+	ChannelVoiceJoinManual(guildID string, channelID string, mute bool, deaf bool) error
+	UpdateGameStatus(idle int, name string) error
+	HeartbeatLatency() time.Duration
+
+	// Backed by the session state
+	UserID() string
+	VoiceState(guildID string, userID string) (*discordgo.VoiceState, error)
+	Connected() bool
+}
+
+// Lavalink operations used by the bot to load tracks and create players.
+type LavalinkClient interface {
+	Player(guildID string) (AudioPlayer, error)
+	LoadItem(ctx context.Context, identifier string) (*lavalink.LoadResult, error)
+	LoadItemHandler(ctx context.Context, identifier string, handler lavalink.AudioLoadResultHandler) error
+	DecodeTrack(track string) (lavalink.AudioTrack, error)
+}
+
+// Player operations used by the bot. Implemented by lavalink.Player.
+type AudioPlayer interface {
+	Play(track lavalink.AudioTrack) error
+	Stop() error
+	Destroy() error
+	Pause(paused bool) error
+	Paused() bool
+	Seek(position lavalink.Duration) error
+	Position() lavalink.Duration
+	PlayingTrack() lavalink.AudioTrack
+	Volume() int
+	SetVolume(volume int) error
+	Node() lavalink.Node
+	ChangeNode(node lavalink.Node)
+	AddListener(listener any)
+}
+
+type discordSession struct {
+	*discordgo.Session
+}
+
+func NewSession(s *discordgo.Session) Session {
+	return discordSession{Session: s}
+}
+
+func (s discordSession) UserID() string {
+	return s.State.User.ID
+}
+
+func (s discordSession) VoiceState(guildID string, userID string) (*discordgo.VoiceState, error) {
+	return s.State.VoiceState(guildID, userID)
+}
+
+// Whether the gateway is connected. Discordgo reconnects on its own.
+func (s discordSession) Connected() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.DataReady
+}
+
+type linkClient struct {
+	link    *dgolink.Link
+	players *playerSync
+}
+
+func NewLavalinkClient(link *dgolink.Link) LavalinkClient {
+	players := &playerSync{states: map[string]lavalink.PlayerState{}}
+	link.AddPlugins(players)
+	return linkClient{link: link, players: players}
+}
+
+func (l linkClient) Player(guildID string) (AudioPlayer, error) {
+	id, err := snowflake.Parse(guildID)
+	if err != nil {
+		return nil, err
+	}
+	return syncedPlayer{Player: l.link.Player(id), players: l.players}, nil
+}
+
+// Disgolink players set their playing track after sending the command to lavalink, while the listen goroutine
+// of the node reads it for every track event. Lavalink may answer before the track is set, so commands changing
+// the track hold the read lock and the node waits for them before handling a message.
+// The node also writes player updates into the players unguarded, so their states are kept here instead.
+type playerSync struct {
+	lavalink.PluginEventAdapter
+	mu     sync.RWMutex
+	states map[string]lavalink.PlayerState // maps guild IDs to the last player update
+}
+
+func (p *playerSync) OnNodeMessageIn(_ lavalink.Node, data []byte) {
+	var update struct {
+		Op      lavalink.OpType      `json:"op"`
+		GuildID string               `json:"guildId"`
+		State   lavalink.PlayerState `json:"state"`
+	}
+	isUpdate := json.Unmarshal(data, &update) == nil && update.Op == lavalink.OpTypePlayerUpdate
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if isUpdate {
+		p.states[update.GuildID] = update.State
+	}
+}
+
+func (p *playerSync) OnDestroyPlayer(player lavalink.Player) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.states, player.GuildID().String())
+}
+
+func (p *playerSync) state(guildID string) lavalink.PlayerState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.states[guildID]
+}
+
+type syncedPlayer struct {
+	lavalink.Player
+	players *playerSync
+}
+
+func (p syncedPlayer) Play(track lavalink.AudioTrack) error {
+	p.players.mu.RLock()
+	defer p.players.mu.RUnlock()
+	return p.Player.Play(track)
+}
+
+func (p syncedPlayer) Stop() error {
+	p.players.mu.RLock()
+	defer p.players.mu.RUnlock()
+	return p.Player.Stop()
+}
+
+// Estimates the position like disgolink players do, from the last player update and the time passed since.
+func (p syncedPlayer) Position() lavalink.Duration {
+	track := p.PlayingTrack()
+	if track == nil {
+		return 0
+	}
+	state := p.players.state(p.GuildID().String())
+	position := state.Position
+	if !p.Paused() {
+		position += lavalink.Duration(time.Since(state.Time.Time).Milliseconds())
+	}
+	if position > track.Info().Length {
+		return track.Info().Length
+	}
+	if position < 0 {
+		return 0
+	}
+	return position
+}
+
+func (p syncedPlayer) Connected() bool {
+	return p.players.state(p.GuildID().String()).Connected
+}
+
+func (l linkClient) LoadItem(ctx context.Context, identifier string) (*lavalink.LoadResult, error) {
+	return l.link.BestRestClient().LoadItem(ctx, identifier)
+}
+
+func (l linkClient) LoadItemHandler(ctx context.Context, identifier string, handler lavalink.AudioLoadResultHandler) error {
+	return l.link.BestRestClient().LoadItemHandler(ctx, identifier, handler)
+}
+
+func (l linkClient) DecodeTrack(track string) (lavalink.AudioTrack, error) {
+	return l.link.DecodeTrack(track)
+}
@@ -0,0 +1,332 @@
+package gobot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	fakeLavalinkPassword = "youshallnotpass"
+	fakeLavalinkTimeout  = 5 * time.Second
+)
+
+// In-process stand-in for a Lavalink v3 node. Serves the websocket and REST endpoints used by the bot,
+// answers loadtracks with canned results and emits track events like a real node.
+// Played tracks keep playing until the test finishes, fails or replaces them.
+type fakeLavalinkServer struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	results map[string]lavalink.LoadResult // maps identifiers to their load result, unknown identifiers have no matches
+	conn    *websocket.Conn                // latest websocket connection of the bot
+	playing map[string]string              // maps guild IDs to the encoded playing track
+
+	ops         chan fakeLavalinkOp // ops received from the bot
+	connections chan struct{}       // signals every opened websocket connection
+}
+
+// Op sent by the bot. Only the fields of the ops the bot uses are decoded.
+type fakeLavalinkOp struct {
+	Op       lavalink.OpType `json:"op"`
+	GuildID  string          `json:"guildId"`
+	Track    string          `json:"track"`
+	Pause    bool            `json:"pause"`
+	Position int64           `json:"position"`
+	Volume   int             `json:"volume"`
+}
+
+func newFakeLavalinkServer(t *testing.T) *fakeLavalinkServer {
+	t.Helper()
+	f := &fakeLavalinkServer{
+		results:     map[string]lavalink.LoadResult{},
+		playing:     map[string]string{},
+		ops:         make(chan fakeLavalinkOp, 256),
+		connections: make(chan struct{}, 16),
+	}
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.close)
+	return f
+}
+
+// Points the lavalink node of the configuration to the server.
+func (f *fakeLavalinkServer) configure(t *testing.T, conf *Configuration) {
+	t.Helper()
+	serverURL, err := url.Parse(f.server.URL)
+	if err != nil {
+		t.Fatalf("parsing fake lavalink url: %v", err)
+	}
+	conf.LavalinkHost = serverURL.Hostname()
+	conf.LavalinkPort = serverURL.Port()
+	conf.LavalinkPW = fakeLavalinkPassword
+	conf.Secure = false
+}
+
+func (f *fakeLavalinkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != fakeLavalinkPassword {
+		http.Error(w, "invalid password", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/":
+		f.serveWebsocket(w, r)
+	case "/loadtracks":
+		f.mu.Lock()
+		result, ok := f.results[r.URL.Query().Get("identifier")]
+		f.mu.Unlock()
+		if !ok {
+			result = lavalink.LoadResult{LoadType: lavalink.LoadTypeNoMatches, Tracks: []lavalink.RestAudioTrack{}}
+		}
+		f.writeJSON(w, result)
+	case "/decodetrack":
+		track, err := lavalink.DecodeString(r.URL.Query().Get("track"), nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.writeJSON(w, track.Info())
+	case "/version":
+		_, _ = fmt.Fprint(w, "3.7.0")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeLavalinkServer) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeLavalinkServer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("User-Id") == "" {
+		http.Error(w, "missing user id", http.StatusBadRequest)
+		return
+	}
+	conn, err := f.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	// Players do not survive a new connection without resuming
+	f.mu.Lock()
+	f.conn = conn
+	f.playing = map[string]string{}
+	f.mu.Unlock()
+	f.connections <- struct{}{}
+
+	f.send(map[string]any{
+		"op":             lavalink.OpTypeStats,
+		"players":        0,
+		"playingPlayers": 0,
+		"uptime":         1000,
+		"memory":         lavalink.Memory{Used: 1 << 20},
+		"cpu":            lavalink.CPU{Cores: 2, SystemLoad: 0.1, LavalinkLoad: 0.05},
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var op fakeLavalinkOp
+		if err := json.Unmarshal(data, &op); err != nil {
+			continue
+		}
+		f.handleOp(op)
+		select {
+		case f.ops <- op:
+		default:
+		}
+	}
+}
+
+// Reacts to ops like lavalink does once a track is loaded.
+func (f *fakeLavalinkServer) handleOp(op fakeLavalinkOp) {
+	switch op.Op {
+	case lavalink.OpTypePlay:
+		f.mu.Lock()
+		replaced, ok := f.playing[op.GuildID]
+		f.playing[op.GuildID] = op.Track
+		f.mu.Unlock()
+		if ok {
+			f.sendTrackEvent(lavalink.EventTypeTrackEnd, op.GuildID, replaced, map[string]any{"reason": lavalink.AudioTrackEndReasonReplaced})
+		}
+		f.sendTrackEvent(lavalink.EventTypeTrackStart, op.GuildID, op.Track, nil)
+
+		var position lavalink.Duration
+		if track, err := lavalink.DecodeString(op.Track, nil); err == nil {
+			position = track.Info().Position
+		}
+		f.sendPlayerUpdate(op.GuildID, position)
+	case lavalink.OpTypeStop:
+		if track, ok := f.takePlaying(op.GuildID); ok {
+			f.sendTrackEvent(lavalink.EventTypeTrackEnd, op.GuildID, track, map[string]any{"reason": lavalink.AudioTrackEndReasonStopped})
+		}
+	case lavalink.OpTypeSeek:
+		f.sendPlayerUpdate(op.GuildID, lavalink.Duration(op.Position))
+	case lavalink.OpTypeDestroy:
+		f.takePlaying(op.GuildID)
+	}
+}
+
+func (f *fakeLavalinkServer) takePlaying(guildID string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	track, ok := f.playing[guildID]
+	delete(f.playing, guildID)
+	return track, ok
+}
+
+func (f *fakeLavalinkServer) send(message any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return
+	}
+	_ = f.conn.WriteJSON(message)
+}
+
+func (f *fakeLavalinkServer) sendTrackEvent(eventType lavalink.EventType, guildID string, track string, fields map[string]any) {
+	event := map[string]any{
+		"op":      lavalink.OpTypeEvent,
+		"type":    eventType,
+		"guildId": guildID,
+		"track":   track,
+	}
+	for key, value := range fields {
+		event[key] = value
+	}
+	f.send(event)
+}
+
+func (f *fakeLavalinkServer) sendPlayerUpdate(guildID string, position lavalink.Duration) {
+	f.send(map[string]any{
+		"op":      lavalink.OpTypePlayerUpdate,
+		"guildId": guildID,
+		"state": map[string]any{
+			"time":      time.Now().UnixMilli(),
+			"position":  position.Milliseconds(),
+			"connected": true,
+		},
+	})
+}
+
+// Answers loadtracks requests for the identifier with the tracks.
+func (f *fakeLavalinkServer) addResult(t *testing.T, identifier string, loadType lavalink.LoadType, tracks ...lavalink.AudioTrack) {
+	t.Helper()
+	result := lavalink.LoadResult{LoadType: loadType, Tracks: []lavalink.RestAudioTrack{}}
+	for _, track := range tracks {
+		encoded, err := lavalink.EncodeToString(track, nil)
+		if err != nil {
+			t.Fatalf("encoding track %s: %v", track.Info().Identifier, err)
+		}
+		result.Tracks = append(result.Tracks, lavalink.RestAudioTrack{Track: encoded, Info: track.Info()})
+	}
+	if loadType == lavalink.LoadTypePlaylistLoaded {
+		result.PlaylistInfo = &lavalink.PlaylistInfo{Name: identifier, SelectedTrack: -1}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[identifier] = result
+}
+
+// Lets loadtracks requests for the identifier fail with the message.
+func (f *fakeLavalinkServer) addLoadFailure(identifier string, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[identifier] = lavalink.LoadResult{
+		LoadType:  lavalink.LoadTypeLoadFailed,
+		Tracks:    []lavalink.RestAudioTrack{},
+		Exception: &lavalink.FriendlyException{Message: message, Severity: lavalink.SeverityCommon},
+	}
+}
+
+// Ends the playing track of the guild as if it played to the end.
+func (f *fakeLavalinkServer) finishTrack(t *testing.T, guildID string) {
+	t.Helper()
+	track, ok := f.takePlaying(guildID)
+	if !ok {
+		t.Fatalf("no track is playing in guild %s", guildID)
+	}
+	if decoded, err := lavalink.DecodeString(track, nil); err == nil {
+		f.sendPlayerUpdate(guildID, decoded.Info().Length)
+	}
+	f.sendTrackEvent(lavalink.EventTypeTrackEnd, guildID, track, map[string]any{"reason": lavalink.AudioTrackEndReasonFinished})
+}
+
+// Fails the playing track of the guild with an exception like a broken stream does.
+func (f *fakeLavalinkServer) failTrack(t *testing.T, guildID string, message string) {
+	t.Helper()
+	track, ok := f.takePlaying(guildID)
+	if !ok {
+		t.Fatalf("no track is playing in guild %s", guildID)
+	}
+	f.sendTrackEvent(lavalink.EventTypeTrackException, guildID, track, map[string]any{
+		"exception": lavalink.FriendlyException{Message: message, Severity: lavalink.SeverityFault},
+	})
+	f.sendTrackEvent(lavalink.EventTypeTrackEnd, guildID, track, map[string]any{"reason": lavalink.AudioTrackEndReasonLoadFailed})
+}
+
+// Reports the playing track of the guild as stuck without ending it.
+func (f *fakeLavalinkServer) stickTrack(t *testing.T, guildID string, threshold lavalink.Duration) {
+	t.Helper()
+	f.mu.Lock()
+	track, ok := f.playing[guildID]
+	f.mu.Unlock()
+	if !ok {
+		t.Fatalf("no track is playing in guild %s", guildID)
+	}
+	f.sendTrackEvent(lavalink.EventTypeTrackStuck, guildID, track, map[string]any{"thresholdMs": threshold.Milliseconds()})
+}
+
+// Closes the websocket connection like a restarting node. The bot is expected to reconnect.
+func (f *fakeLavalinkServer) dropConnection() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		_ = f.conn.Close()
+		f.conn = nil
+	}
+}
+
+// Waits for the bot to open a websocket connection.
+func (f *fakeLavalinkServer) waitConnection(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.connections:
+	case <-time.After(fakeLavalinkTimeout):
+		t.Fatal("bot did not connect to the fake lavalink node")
+	}
+}
+
+// Waits for the next op of the type, skipping other ops.
+func (f *fakeLavalinkServer) waitOp(t *testing.T, opType lavalink.OpType) fakeLavalinkOp {
+	t.Helper()
+	timeout := time.After(fakeLavalinkTimeout)
+	for {
+		select {
+		case op := <-f.ops:
+			if op.Op == opType {
+				return op
+			}
+		case <-timeout:
+			t.Fatalf("fake lavalink node did not receive a %s op", opType)
+		}
+	}
+}
+
+func (f *fakeLavalinkServer) close() {
+	f.dropConnection()
+	f.server.Close()
+}
@@ -0,0 +1,239 @@
+package gobot
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// File extensions supported by the lavalink local source
+var libraryExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".wav":  true,
+	".ogg":  true,
+	".opus": true,
+	".m4a":  true,
+	".mp4":  true,
+	".aac":  true,
+}
+
+// Minimum fuzzy score for a library track to count as a match
+const libraryMatchThreshold = 0.5
+
+// Index of the audio files in the configured library directory.
+// The lavalink node needs access to the same directory to play the files.
+type Library struct {
+	Dir      string
+	Tracks   []LibraryTrack
+	TracksMu sync.RWMutex
+	ScanMu   sync.Mutex // serializes scans, so a rescan can't be overwritten by an older one finishing later
+}
+
+type LibraryTrack struct {
+	Title  string
+	Artist string
+	Album  string
+	Path   string
+	Track  lavalink.AudioTrack
+}
+
+type libraryMatch struct {
+	track LibraryTrack
+	score float64
+}
+
+func NewLibrary(dir string) *Library {
+	return &Library{Dir: dir}
+}
+
+// Walks the library directory and loads every audio file through lavalink. Files that can't be loaded
+// are logged and skipped, only a canceled context aborts the scan.
+// Title and artist are taken from the tags read by lavalink. Lavalink does not read album tags,
+// so the album is the folder containing the file.
+func (l *Library) Scan(ctx context.Context, client LavalinkClient) (int, error) {
+	l.ScanMu.Lock()
+	defer l.ScanMu.Unlock()
+	Logger.Info("Scanning music library in ", l.Dir)
+
+	var tracks []LibraryTrack
+	err := filepath.WalkDir(l.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			Logger.Warn("Could not access library path ", path, ": ", err)
+			return nil
+		}
+		if d.IsDir() || !libraryExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			Logger.Warn("Could not resolve library path ", path, ": ", err)
+			return nil
+		}
+
+		loadStart := time.Now()
+		result, err := client.LoadItem(ctx, absPath)
+		observeLoad("library", loadStart)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			Logger.Warn("Could not load library file ", absPath, ": ", err)
+			return nil
+		}
+		if result.LoadType != lavalink.LoadTypeTrackLoaded || len(result.Tracks) == 0 {
+			Logger.Debug("Lavalink could not load library file ", absPath, ": ", result.LoadType)
+			return nil
+		}
+
+		track, err := client.DecodeTrack(result.Tracks[0].Track)
+		if err != nil {
+			Logger.Warn("Could not decode library track ", absPath, ": ", err)
+			return nil
+		}
+
+		tracks = append(tracks, newLibraryTrack(l.Dir, absPath, track))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	l.TracksMu.Lock()
+	l.Tracks = tracks
+	l.TracksMu.Unlock()
+
+	Logger.Info("Indexed ", len(tracks), " library tracks.")
+	return len(tracks), nil
+}
+
+func newLibraryTrack(dir string, path string, track lavalink.AudioTrack) LibraryTrack {
+	title := track.Info().Title
+	if title == "" || title == "Unknown title" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	artist := track.Info().Author
+	if artist == "Unknown artist" {
+		artist = ""
+	}
+	album := ""
+	if parent := filepath.Dir(path); parent != filepath.Clean(dir) {
+		album = filepath.Base(parent)
+	}
+
+	return LibraryTrack{
+		Title:  title,
+		Artist: artist,
+		Album:  album,
+		Path:   path,
+		Track:  track,
+	}
+}
+
+// Returns up to limit tracks fuzzy matching the query, best match first.
+func (l *Library) Search(query string, limit int) []LibraryTrack {
+	l.TracksMu.RLock()
+	defer l.TracksMu.RUnlock()
+
+	query = normalizeLibraryText(query)
+	var matches []libraryMatch
+	for _, track := range l.Tracks {
+		if score := track.score(query); score >= libraryMatchThreshold {
+			matches = append(matches, libraryMatch{track: track, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	var tracks []LibraryTrack
+	for i := 0; i < len(matches) && i < limit; i++ {
+		tracks = append(tracks, matches[i].track)
+	}
+	return tracks
+}
+
+// Returns the best match for the query.
+func (l *Library) Find(query string) (LibraryTrack, error) {
+	if tracks := l.Search(query, 1); len(tracks) > 0 {
+		return tracks[0], nil
+	}
+	return LibraryTrack{}, errors.New("no library track matches " + query)
+}
+
+// Scores how well the normalized query matches the title, optionally combined with artist or album.
+func (t LibraryTrack) score(query string) float64 {
+	var best float64
+	candidates := []string{t.Title, t.Artist + " " + t.Title, t.Title + " " + t.Artist, t.Album + " " + t.Title}
+	for _, candidate := range candidates {
+		candidate = normalizeLibraryText(candidate)
+		var score float64
+		if strings.Contains(candidate, query) {
+			score = 1
+		} else {
+			score = similarity(query, candidate)
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func normalizeLibraryText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// Similarity between 0 and 1 based on the levenshtein distance.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
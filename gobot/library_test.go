@@ -0,0 +1,117 @@
+package gobot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Creates empty audio files in the directory and registers them with the fake lavalink.
+func libraryFiles(t *testing.T, link *fakeLavalink, dir string, files map[string]string) {
+	t.Helper()
+	for file, title := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if title != "" {
+			link.tracks[path] = []lavalink.AudioTrack{lavalink.NewAudioTrack(lavalink.AudioTrackInfo{Title: title, Author: "Artist", SourceName: "local"})}
+		}
+	}
+}
+
+func TestLibraryScan(t *testing.T) {
+	dir := t.TempDir()
+	link := newFakeLavalink()
+	libraryFiles(t, link, dir, map[string]string{
+		"song.mp3":             "Song",
+		"Album/track.flac":     "Track",
+		"broken.mp3":           "Broken",
+		"unsupported.mp3":      "",
+		"cover.jpg":            "Cover",
+		"Album/Disc 2/end.ogg": "End",
+	})
+	link.loadErrors[filepath.Join(dir, "broken.mp3")] = errors.New("connection refused")
+
+	library := NewLibrary(dir)
+	count, err := library.Scan(context.Background(), link)
+	if err != nil {
+		t.Fatalf("failing files aborted the scan: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 tracks, got %d: %+v", count, library.Tracks)
+	}
+
+	albums := map[string]string{}
+	for _, track := range library.Tracks {
+		albums[track.Title] = track.Album
+	}
+	expected := map[string]string{"Song": "", "Track": "Album", "End": "Disc 2"}
+	for title, album := range expected {
+		if got, ok := albums[title]; !ok || got != album {
+			t.Errorf("expected %s in album %q, got %q (indexed %v)", title, album, got, ok)
+		}
+	}
+}
+
+func TestLibraryScanCanceled(t *testing.T) {
+	dir := t.TempDir()
+	link := newFakeLavalink()
+	libraryFiles(t, link, dir, map[string]string{"song.mp3": "Song"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	library := NewLibrary(dir)
+	if _, err := library.Scan(ctx, link); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the canceled scan to fail, got %v", err)
+	}
+}
+
+func TestLibraryRescanPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		djRole     string
+		roles      []string
+		permission int64
+		allowed    bool
+	}{
+		{name: "member without DJ role setting", allowed: false},
+		{name: "manager without DJ role setting", permission: discordgo.PermissionManageServer, allowed: true},
+		{name: "DJ", djRole: "500", roles: []string{"500"}, allowed: true},
+		{name: "member without DJ role", djRole: "500", allowed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, link := newTestBot(t)
+			dir := t.TempDir()
+			libraryFiles(t, link, dir, map[string]string{"song.mp3": "Song"})
+			bot.Library = NewLibrary(dir)
+			if test.djRole != "" {
+				if err := bot.Settings.Set(testGuildID, "dj_role", test.djRole); err != nil {
+					t.Fatal(err)
+				}
+			}
+			interaction := commandInteraction("library", subcommand("rescan"))
+			interaction.Member.Roles = test.roles
+			interaction.Member.Permissions = test.permission
+
+			err := libraryCommand(session, interaction, bot)
+
+			if allowed := !errors.Is(err, ErrPermissionDenied); allowed != test.allowed {
+				t.Errorf("expected allowed %v, got error %v", test.allowed, err)
+			}
+			if scanned := len(bot.Library.Tracks) > 0; scanned != test.allowed {
+				t.Errorf("expected scanned %v", test.allowed)
+			}
+		})
+	}
+}
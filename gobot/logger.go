@@ -1,6 +1,7 @@
 package gobot
 
 import (
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -17,17 +18,20 @@ func init() {
 	Logger.Formatter.(*logrus.TextFormatter).FullTimestamp = true
 }
 
+// Applies the log configurations. It is called again when the configuration is reloaded,
+// so formatters are always created from scratch.
 func SetLoggerConfig(conf Configuration) {
+	var formatter logrus.Formatter
 	switch conf.LogFormat {
 	case "text":
-		// default already set
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
 	case "json":
-		Logger.Formatter = new(logrus.JSONFormatter)
+		formatter = new(logrus.JSONFormatter)
 	case "plain":
-		Logger.Formatter = new(logrus.TextFormatter)
-		Logger.Formatter.(*logrus.TextFormatter).DisableColors = true
+		formatter = &logrus.TextFormatter{FullTimestamp: true, DisableColors: true}
 	default:
 		Logger.Warn("Logging format not supported. Falling back to default.")
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
 	}
 
 	switch conf.LogLevel {
@@ -39,38 +43,44 @@ func SetLoggerConfig(conf Configuration) {
 		Logger.SetLevel(logrus.InfoLevel)
 	default:
 		Logger.Warn("Log level not supported. Falling back to info default.")
+		Logger.SetLevel(logrus.InfoLevel)
 	}
 
-	if _, ok := Logger.Formatter.(*logrus.TextFormatter); ok {
-		switch conf.LogTimeStamp {
-		case "on":
-			Logger.Formatter.(*logrus.TextFormatter).DisableTimestamp = false
-		case "off":
-			Logger.Formatter.(*logrus.TextFormatter).DisableTimestamp = true
-		default:
-			Logger.Warn("Log time stamp not supported. Falling back to on default.")
-		}
+	var disableTimestamp bool
+	switch conf.LogTimeStamp {
+	case "on":
+		disableTimestamp = false
+	case "off":
+		disableTimestamp = true
+	default:
+		Logger.Warn("Log time stamp not supported. Falling back to on default.")
+	}
+	if textFormatter, ok := formatter.(*logrus.TextFormatter); ok {
+		textFormatter.DisableTimestamp = disableTimestamp
 	} else {
-		switch conf.LogTimeStamp {
-		case "on":
-			Logger.Formatter.(*logrus.JSONFormatter).DisableTimestamp = false
-		case "off":
-			Logger.Formatter.(*logrus.JSONFormatter).DisableTimestamp = true
-		default:
-			Logger.Warn("Log time stamp not supported. Falling back to on default.")
-		}
+		formatter.(*logrus.JSONFormatter).DisableTimestamp = disableTimestamp
 	}
+	Logger.SetFormatter(formatter)
 
 	if conf.LogFile != "none" {
 		if file, err := os.OpenFile(conf.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666); err != nil {
 			Logger.Warn("Failed to log to specified file + " + conf.LogFile + ". Falling back to default stdout.")
-			Logger.Out = os.Stdout
+			setLoggerOutput(os.Stdout)
 		} else {
 			Logger.Info("Successfully accessed log file " + conf.LogFile + ".")
-			Logger.Out = file
+			setLoggerOutput(file)
 		}
 	} else {
 		Logger.Warn("Log file not specified. Falling back to default stdout.")
-		Logger.Out = os.Stdout
+		setLoggerOutput(os.Stdout)
+	}
+}
+
+// Sets the log output and closes the previous log file.
+func setLoggerOutput(out io.Writer) {
+	previous := Logger.Out
+	Logger.SetOutput(out)
+	if file, ok := previous.(*os.File); ok && file != out && file != os.Stdout && file != os.Stderr {
+		file.Close()
 	}
 }
@@ -0,0 +1,315 @@
+package gobot
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Language the bot falls back to for missing messages and unsupported locales
+const defaultLanguage = "en"
+
+// Language setting that follows the locale of the user of an interaction
+const autoLanguage = "auto"
+
+// Theme holding every message. Other themes only override some of them.
+const defaultTheme = "playful"
+
+// Message catalogs of all languages, one JSON file per language named after it.
+// Built-in themes are subdirectories of locales/themes with the same layout.
+//
+//go:embed locales
+var localeFiles embed.FS
+
+// Catalog of all user-facing messages, loaded once on start up.
+var Messages = mustLoadCatalog(localeFiles)
+
+// Template arguments of a message.
+type Args map[string]interface{}
+
+// Language and theme messages are rendered in. The zero value renders the default theme in the default language.
+type Style struct {
+	Language string
+	Theme    string
+}
+
+// Message templates keyed by language and message key
+type messageSet map[string]map[string]*template.Template
+
+// Message templates of all themes. Keys starting with commands. hold the localized names and descriptions
+// of the application commands and are only read from the default theme.
+type Catalog struct {
+	mu     sync.RWMutex
+	themes map[string]messageSet
+	custom []string // names of the themes loaded from the theme directory
+}
+
+// Loads every <language>.json of the locales directory and the built-in themes. Messages are text/template templates.
+func LoadCatalog(fsys fs.FS) (*Catalog, error) {
+	messages, err := loadMessageSet(fsys, "locales")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := messages[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("message catalog of the default language %s is missing", defaultLanguage)
+	}
+
+	catalog := &Catalog{themes: map[string]messageSet{defaultTheme: messages}}
+	themes, err := fs.ReadDir(fsys, "locales/themes")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, theme := range themes {
+		if !theme.IsDir() {
+			continue
+		}
+		if catalog.themes[theme.Name()], err = loadMessageSet(fsys, path.Join("locales/themes", theme.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
+
+func loadMessageSet(fsys fs.FS, dir string) (messageSet, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := messageSet{}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var texts map[string]string
+		if err := json.Unmarshal(data, &texts); err != nil {
+			return nil, fmt.Errorf("could not parse message catalog %s: %w", file, err)
+		}
+
+		language := strings.TrimSuffix(path.Base(file), ".json")
+		messages[language] = map[string]*template.Template{}
+		for key, text := range texts {
+			tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message %s in %s: %w", key, file, err)
+			}
+			messages[language][key] = tmpl
+		}
+	}
+	return messages, nil
+}
+
+func mustLoadCatalog(fsys fs.FS) *Catalog {
+	catalog, err := LoadCatalog(fsys)
+	if err != nil {
+		Logger.Panic("Invalid message catalog: ", err)
+	}
+	return catalog
+}
+
+// Replaces the custom themes with the subdirectories of the directory, or removes them if the directory is none.
+// Custom themes can't replace built-in ones and only override messages of the default theme.
+func (c *Catalog) LoadCustomThemes(dir string) error {
+	custom := map[string]messageSet{}
+	if dir != "none" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			messages, err := loadMessageSet(os.DirFS(dir), entry.Name())
+			if err != nil {
+				return err
+			}
+			custom[entry.Name()] = messages
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, messages := range custom {
+		if _, ok := c.themes[name]; ok && !containsString(c.custom, name) {
+			return fmt.Errorf("custom theme %s has the name of a built-in theme", name)
+		}
+		for language, templates := range messages {
+			for key := range templates {
+				if _, ok := c.themes[defaultTheme][defaultLanguage][key]; !ok {
+					return fmt.Errorf("custom theme %s has unknown message %s in %s", name, key, language)
+				}
+			}
+		}
+	}
+
+	for _, name := range c.custom {
+		delete(c.themes, name)
+	}
+	c.custom = nil
+	for name, messages := range custom {
+		c.themes[name] = messages
+		c.custom = append(c.custom, name)
+	}
+	return nil
+}
+
+// Renders the message in the style. Messages missing in the theme fall back to the default theme,
+// then to the default language and finally to the key.
+func (c *Catalog) Text(style Style, key string, args Args) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	theme, base := c.themes[style.Theme], c.themes[defaultTheme]
+	var tmpl *template.Template
+	for _, candidate := range []*template.Template{
+		theme[style.Language][key], base[style.Language][key], theme[defaultLanguage][key], base[defaultLanguage][key],
+	} {
+		if candidate != nil {
+			tmpl = candidate
+			break
+		}
+	}
+	if tmpl == nil {
+		Logger.Warn("Missing message ", key)
+		return key
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, args); err != nil {
+		Logger.Warn("Could not render message ", key, ": ", err)
+		return key
+	}
+	return text.String()
+}
+
+// Whether the default theme has the message in the language, without falling back.
+func (c *Catalog) has(language string, key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.themes[defaultTheme][language][key]
+	return ok
+}
+
+// Sorted languages of the catalog.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var languages []string
+	for language := range c.themes[defaultTheme] {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Sorted names of the built-in and custom themes.
+func (c *Catalog) Themes() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var themes []string
+	for theme := range c.themes {
+		themes = append(themes, theme)
+	}
+	sort.Strings(themes)
+	return themes
+}
+
+// Language of the catalog for a discord locale like en-US or ja. Empty if the locale is not supported.
+func (c *Catalog) Match(locale discordgo.Locale) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.themes[defaultTheme][string(locale)]; ok {
+		return string(locale)
+	}
+	base, _, _ := strings.Cut(string(locale), "-")
+	if _, ok := c.themes[defaultTheme][base]; ok {
+		return base
+	}
+	return ""
+}
+
+// Style of the responses to an interaction. The language setting of the guild wins unless it is auto,
+// which picks the locale of the user and then the locale of the guild.
+func (b *Bot) style(i *discordgo.InteractionCreate) Style {
+	settings := b.Settings.Get(i.GuildID)
+	style := Style{Language: settings.Language, Theme: settings.Theme}
+	if style.Language != autoLanguage {
+		return style
+	}
+
+	style.Language = defaultLanguage
+	if language := Messages.Match(i.Locale); language != "" {
+		style.Language = language
+	} else if i.GuildLocale != nil {
+		if language := Messages.Match(*i.GuildLocale); language != "" {
+			style.Language = language
+		}
+	}
+	return style
+}
+
+// Style of messages of a guild that do not answer an interaction, like announcements.
+func (b *Bot) guildStyle(guildID string) Style {
+	settings := b.Settings.Get(guildID)
+	style := Style{Language: settings.Language, Theme: settings.Theme}
+	if style.Language == autoLanguage {
+		style.Language = defaultLanguage
+	}
+	return style
+}
+
+// Renders the message in the style of the interaction.
+func (b *Bot) text(i *discordgo.InteractionCreate, key string, args Args) string {
+	return Messages.Text(b.style(i), key, args)
+}
+
+// Adds the names and descriptions of the catalog to the commands and their options for all discord locales
+// of a supported language. The default language is used as is.
+func localizeCommands(commands []*discordgo.ApplicationCommand) {
+	for _, command := range commands {
+		key := "commands." + strings.ReplaceAll(strings.ToLower(command.Name), " ", "_")
+		if names := commandLocalizations(key + ".name"); len(names) > 0 {
+			command.NameLocalizations = &names
+		}
+		if descriptions := commandLocalizations(key + ".description"); len(descriptions) > 0 {
+			command.DescriptionLocalizations = &descriptions
+		}
+		localizeOptions(key, command.Options)
+	}
+}
+
+func localizeOptions(key string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		optionKey := key + "." + option.Name
+		if descriptions := commandLocalizations(optionKey + ".description"); len(descriptions) > 0 {
+			option.DescriptionLocalizations = descriptions
+		}
+		localizeOptions(optionKey, option.Options)
+	}
+}
+
+func commandLocalizations(key string) map[discordgo.Locale]string {
+	localizations := map[discordgo.Locale]string{}
+	for locale := range discordgo.Locales {
+		language := Messages.Match(locale)
+		if language == "" || language == defaultLanguage {
+			continue
+		}
+		if Messages.has(language, key) {
+			localizations[locale] = Messages.Text(Style{Language: language}, key, nil)
+		}
+	}
+	return localizations
+}
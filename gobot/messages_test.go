@@ -0,0 +1,238 @@
+package gobot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCatalogLanguages(t *testing.T) {
+	field, _ := guildSettingField("language")
+	var settingLanguages []string
+	for _, language := range strings.Split(field.Tag.Get("enum"), ",") {
+		if language != autoLanguage {
+			settingLanguages = append(settingLanguages, language)
+		}
+	}
+	if languages := Messages.Languages(); !reflect.DeepEqual(languages, settingLanguages) {
+		t.Errorf("language setting allows %v but the catalog has %v", settingLanguages, languages)
+	}
+
+	// Every language translates all messages, commands are described in english by the definitions
+	for _, language := range Messages.Languages() {
+		for key := range Messages.themes[defaultTheme][defaultLanguage] {
+			if _, ok := Messages.themes[defaultTheme][language][key]; !ok {
+				t.Errorf("%s is missing message %s", language, key)
+			}
+		}
+		for key := range Messages.themes[defaultTheme][language] {
+			if _, ok := Messages.themes[defaultTheme][defaultLanguage][key]; !ok && !strings.HasPrefix(key, "commands.") {
+				t.Errorf("%s has unknown message %s", language, key)
+			}
+		}
+	}
+}
+
+func TestCatalogText(t *testing.T) {
+	tests := []struct {
+		language string
+		key      string
+		args     Args
+		text     string
+	}{
+		{language: "en", key: "set.done", args: Args{"Mode": "all"}, text: "Set mode to: all"},
+		{language: "ja", key: "set.done", args: Args{"Mode": "all"}, text: "モードを all に設定しました"},
+		{language: "de", key: "show.empty", text: "Playlist is empty."},
+		{language: "en", key: "set.done", text: "set.done"},
+		{language: "en", key: "unknown.key", text: "unknown.key"},
+	}
+
+	for _, test := range tests {
+		if got := Messages.Text(Style{Language: test.language}, test.key, test.args); got != test.text {
+			t.Errorf("%s %s: expected %q, got %q", test.language, test.key, test.text, got)
+		}
+	}
+}
+
+func TestResponseLanguage(t *testing.T) {
+	japanese := discordgo.Japanese
+	tests := []struct {
+		name        string
+		setting     string
+		locale      discordgo.Locale
+		guildLocale *discordgo.Locale
+		response    string
+	}{
+		{name: "user locale", setting: autoLanguage, locale: discordgo.Japanese, response: "ボイスチャンネルに接続していません。なぜ退出させようとするのですか？ :/"},
+		{name: "regional user locale", setting: autoLanguage, locale: discordgo.EnglishGB, response: "I'm not connected to any voice channel. Why are you trying to make me leave? :/"},
+		{name: "guild locale", setting: autoLanguage, locale: discordgo.German, guildLocale: &japanese, response: "ボイスチャンネルに接続していません。なぜ退出させようとするのですか？ :/"},
+		{name: "unsupported locale", setting: autoLanguage, locale: discordgo.German, response: "I'm not connected to any voice channel. Why are you trying to make me leave? :/"},
+		{name: "guild setting", setting: "en", locale: discordgo.Japanese, response: "I'm not connected to any voice channel. Why are you trying to make me leave? :/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bot, session, _ := newTestBot(t)
+			if err := bot.Settings.Set(testGuildID, "language", test.setting); err != nil {
+				t.Fatalf("setting language: %v", err)
+			}
+			interaction := commandInteraction("leave")
+			interaction.Locale = test.locale
+			interaction.GuildLocale = test.guildLocale
+
+			leaveCommand(session, interaction, bot)
+
+			if got := session.lastResponse(t); got != test.response {
+				t.Errorf("expected %q, got %q", test.response, got)
+			}
+		})
+	}
+}
+
+func TestLocalizedCommands(t *testing.T) {
+	namePattern := regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+	keys := map[string]bool{}
+	var collect func(key string, options []*discordgo.ApplicationCommandOption)
+	collect = func(key string, options []*discordgo.ApplicationCommandOption) {
+		for _, option := range options {
+			keys[key+"."+option.Name+".description"] = true
+			collect(key+"."+option.Name, option.Options)
+		}
+	}
+
+	for _, command := range ApplicationCommands() {
+		key := "commands." + strings.ReplaceAll(strings.ToLower(command.Name), " ", "_")
+		keys[key+".name"] = true
+		keys[key+".description"] = true
+		collect(key, command.Options)
+
+		if command.NameLocalizations == nil {
+			t.Errorf("%s has no localized names", command.Name)
+			continue
+		}
+		for locale, name := range *command.NameLocalizations {
+			if Messages.Match(locale) == defaultLanguage {
+				t.Errorf("%s is localized for %s, which uses the definition", command.Name, locale)
+			}
+			if command.Type != discordgo.MessageApplicationCommand && !namePattern.MatchString(name) {
+				t.Errorf("%s has invalid %s name %q", command.Name, locale, name)
+			}
+		}
+	}
+
+	// Catches typos in the keys, which would silently leave commands untranslated
+	for _, language := range Messages.Languages() {
+		for key := range Messages.themes[defaultTheme][language] {
+			if strings.HasPrefix(key, "commands.") && !keys[key] {
+				t.Errorf("%s localizes unknown command %s", language, key)
+			}
+		}
+	}
+
+	play := ApplicationCommands()[0]
+	if name := (*play.NameLocalizations)[discordgo.Japanese]; name != "再生" {
+		t.Errorf("unexpected japanese name of play: %q", name)
+	}
+	if description := play.Options[0].DescriptionLocalizations[discordgo.Japanese]; description != "再生する曲の検索語。" {
+		t.Errorf("unexpected japanese description of the query option: %q", description)
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, theme := range Messages.Themes() {
+		if theme == defaultTheme {
+			continue
+		}
+		messages := Messages.themes[theme]
+		if _, ok := messages[defaultLanguage]; !ok {
+			t.Errorf("theme %s has no messages in %s", theme, defaultLanguage)
+		}
+		// Missing translations would mix in messages of the default theme
+		for language := range messages {
+			for key := range messages[defaultLanguage] {
+				if _, ok := messages[language][key]; !ok {
+					t.Errorf("theme %s is missing message %s in %s", theme, key, language)
+				}
+			}
+			for key := range messages[language] {
+				if _, ok := Messages.themes[defaultTheme][defaultLanguage][key]; !ok {
+					t.Errorf("theme %s has unknown message %s in %s", theme, key, language)
+				}
+			}
+		}
+	}
+}
+
+func TestCustomThemes(t *testing.T) {
+	catalog, err := LoadCatalog(localeFiles)
+	if err != nil {
+		t.Fatalf("loading catalog: %v", err)
+	}
+	writeTheme := func(dir string, theme string, messages string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, theme), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, theme, "en.json"), []byte(messages), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	writeTheme(dir, "corporate", `{"farewell": "Session ended."}`)
+	if err := catalog.LoadCustomThemes(dir); err != nil {
+		t.Fatalf("loading custom themes: %v", err)
+	}
+	if got := catalog.Text(Style{Language: "ja", Theme: "corporate"}, "farewell", nil); got != "行ってきます、ご主人様" {
+		t.Errorf("expected the japanese message of the default theme, got %q", got)
+	}
+	if got := catalog.Text(Style{Language: "en", Theme: "corporate"}, "farewell", nil); got != "Session ended." {
+		t.Errorf("unexpected custom message: %q", got)
+	}
+
+	invalid := t.TempDir()
+	writeTheme(invalid, "neutral", `{"farewell": "Bye."}`)
+	if err := catalog.LoadCustomThemes(invalid); err == nil {
+		t.Error("custom theme replaced a built-in theme")
+	}
+	invalid = t.TempDir()
+	writeTheme(invalid, "typo", `{"farwell": "Bye."}`)
+	if err := catalog.LoadCustomThemes(invalid); err == nil {
+		t.Error("custom theme with unknown message was loaded")
+	}
+	if themes := catalog.Themes(); !reflect.DeepEqual(themes, []string{"corporate", "neutral", "playful"}) {
+		t.Errorf("failed loads changed the themes: %v", themes)
+	}
+
+	if err := catalog.LoadCustomThemes("none"); err != nil {
+		t.Fatalf("removing custom themes: %v", err)
+	}
+	if themes := catalog.Themes(); !reflect.DeepEqual(themes, []string{"neutral", "playful"}) {
+		t.Errorf("custom themes were not removed: %v", themes)
+	}
+}
+
+func TestNeutralTheme(t *testing.T) {
+	bot, session, _ := newTestBot(t)
+	if err := bot.Settings.Set(testGuildID, "theme", "unknown"); err == nil {
+		t.Error("unknown theme was accepted")
+	}
+	if err := bot.Settings.Set(testGuildID, "theme", "neutral"); err != nil {
+		t.Fatalf("setting theme: %v", err)
+	}
+
+	playCommand(session, commandInteraction("play", stringOption("query", "nothing")), bot)
+	if followup := session.lastFollowup(t); followup.Content != "No matches found for your query." || len(followup.Components) != 0 {
+		t.Errorf("expected no matches without button, got %q with %d components", followup.Content, len(followup.Components))
+	}
+
+	leaveCommand(session, commandInteraction("leave"), bot)
+	if got := session.lastResponse(t); got != "I'm not connected to a voice channel." {
+		t.Errorf("unexpected response: %q", got)
+	}
+}
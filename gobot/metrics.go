@@ -0,0 +1,223 @@
+package gobot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of the commandsTotal metric
+const (
+	commandResultOK      = "ok"
+	commandResultDenied  = "denied"
+	commandResultUnknown = "unknown"
+	commandResultError   = "error"
+)
+
+// Result of a command by the error its handler returned.
+func commandResult(err error) string {
+	switch {
+	case err == nil:
+		return commandResultOK
+	case errors.Is(err, ErrPermissionDenied):
+		return commandResultDenied
+	}
+	return commandResultError
+}
+
+var (
+	commandsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gobot_commands_total",
+		Help: "Handled application commands by name and result.",
+	}, []string{"command", "result"})
+
+	tracksPlayed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gobot_tracks_played_total",
+		Help: "Started tracks by source.",
+	}, []string{"source"})
+
+	trackExceptions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gobot_track_exceptions_total",
+		Help: "Tracks that failed with an exception.",
+	})
+
+	tracksStuck = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gobot_tracks_stuck_total",
+		Help: "Tracks that got stuck.",
+	})
+
+	loadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gobot_lavalink_load_duration_seconds",
+		Help:    "Latency of loading tracks from lavalink by origin.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"origin"})
+)
+
+// Node stats are sent by lavalink every minute, so they are collected on scrape instead of being updated
+var (
+	nodeUpDesc             = nodeDesc("gobot_lavalink_node_up", "Whether the lavalink node is connected.")
+	nodePlayersDesc        = nodeDesc("gobot_lavalink_node_players", "Players on the lavalink node.")
+	nodePlayingPlayersDesc = nodeDesc("gobot_lavalink_node_playing_players", "Playing players on the lavalink node.")
+	nodeUptimeDesc         = nodeDesc("gobot_lavalink_node_uptime_seconds", "Uptime of the lavalink node.")
+	nodeMemoryUsedDesc     = nodeDesc("gobot_lavalink_node_memory_used_bytes", "Used memory of the lavalink node.")
+	nodeSystemLoadDesc     = nodeDesc("gobot_lavalink_node_system_load", "System CPU load of the lavalink node.")
+	nodeLavalinkLoadDesc   = nodeDesc("gobot_lavalink_node_lavalink_load", "CPU load of the lavalink process.")
+	nodeFramesSentDesc     = nodeDesc("gobot_lavalink_node_frames_sent", "Audio frames sent in the last minute.")
+	nodeFramesNulledDesc   = nodeDesc("gobot_lavalink_node_frames_nulled", "Audio frames nulled in the last minute.")
+	nodeFramesDeficitDesc  = nodeDesc("gobot_lavalink_node_frames_deficit", "Audio frames missing in the last minute.")
+)
+
+// Players are read from the player managers on scrape, so the gauges can't drift from the actual players
+var (
+	activePlayersDesc = prometheus.NewDesc("gobot_active_players", "Guilds with an active player.", nil, nil)
+	queueLengthDesc   = prometheus.NewDesc("gobot_queue_length", "Queued songs by guild.", []string{"guild"}, nil)
+)
+
+func nodeDesc(name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(name, help, []string{"node"}, nil)
+}
+
+// Collects the stats of all lavalink nodes.
+type nodeCollector struct {
+	link  lavalink.Lavalink
+	nodes *NodeMonitor
+}
+
+func (c nodeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		nodeUpDesc, nodePlayersDesc, nodePlayingPlayersDesc, nodeUptimeDesc, nodeMemoryUsedDesc,
+		nodeSystemLoadDesc, nodeLavalinkLoadDesc, nodeFramesSentDesc, nodeFramesNulledDesc, nodeFramesDeficitDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (c nodeCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, node := range c.link.Nodes() {
+		status, stats := c.nodes.State(node)
+		up := 0.0
+		if status == lavalink.Connected {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(nodeUpDesc, prometheus.GaugeValue, up, node.Name())
+
+		if stats == nil {
+			continue
+		}
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, node.Name())
+		}
+		gauge(nodePlayersDesc, float64(stats.Players))
+		gauge(nodePlayingPlayersDesc, float64(stats.PlayingPlayers))
+		gauge(nodeUptimeDesc, float64(stats.Uptime.Milliseconds())/1000)
+		gauge(nodeMemoryUsedDesc, float64(stats.Memory.Used))
+		gauge(nodeSystemLoadDesc, stats.CPU.SystemLoad)
+		gauge(nodeLavalinkLoadDesc, stats.CPU.LavalinkLoad)
+		if stats.FrameStats != nil {
+			gauge(nodeFramesSentDesc, float64(stats.FrameStats.Sent))
+			gauge(nodeFramesNulledDesc, float64(stats.FrameStats.Nulled))
+			gauge(nodeFramesDeficitDesc, float64(stats.FrameStats.Deficit))
+		}
+	}
+}
+
+// Collects the active players and their queue lengths.
+type playerCollector struct {
+	bot *Bot
+}
+
+func (c playerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activePlayersDesc
+	ch <- queueLengthDesc
+}
+
+func (c playerCollector) Collect(ch chan<- prometheus.Metric) {
+	managers := c.bot.managers()
+	ch <- prometheus.MustNewConstMetric(activePlayersDesc, prometheus.GaugeValue, float64(len(managers)))
+	for _, manager := range managers {
+		manager.QueueMu.Lock()
+		length := len(manager.Queue)
+		manager.QueueMu.Unlock()
+		ch <- prometheus.MustNewConstMetric(queueLengthDesc, prometheus.GaugeValue, float64(length), manager.GuildID)
+	}
+}
+
+// Counts the event. Called where events are published rather than from a bus subscriber,
+// since the bus drops events for slow subscribers and counters have to be exact.
+func recordMetrics(event interface{}) {
+	switch event := event.(type) {
+	case CommandEvent:
+		commandsTotal.WithLabelValues(event.Command, event.Result).Inc()
+	case PlayerEvent:
+		switch event.Type {
+		case EventTrackStart:
+			tracksPlayed.WithLabelValues(event.Track.Source).Inc()
+		case EventTrackException:
+			trackExceptions.Inc()
+		case EventTrackStuck:
+			tracksStuck.Inc()
+		}
+	}
+}
+
+// Registers the metrics read from the discord session, lavalink and the players on scrape.
+func registerMetrics(s *discordgo.Session, bot *Bot) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "gobot_discord_gateway_latency_seconds",
+		Help: "Latency of the last discord gateway heartbeat.",
+	}, func() float64 {
+		return s.HeartbeatLatency().Seconds()
+	}))
+	prometheus.MustRegister(nodeCollector{link: bot.Link, nodes: bot.Nodes})
+	prometheus.MustRegister(playerCollector{bot: bot})
+}
+
+func observeLoad(origin string, start time.Time) {
+	loadDuration.WithLabelValues(origin).Observe(time.Since(start).Seconds())
+}
+
+// Observes the load latency before handing the result on, so the time spent handling it is not included.
+type timedLoadHandler struct {
+	handler lavalink.AudioLoadResultHandler
+	origin  string
+	start   time.Time
+}
+
+func (h timedLoadHandler) TrackLoaded(track lavalink.AudioTrack) {
+	observeLoad(h.origin, h.start)
+	h.handler.TrackLoaded(track)
+}
+
+func (h timedLoadHandler) PlaylistLoaded(playlist lavalink.AudioPlaylist) {
+	observeLoad(h.origin, h.start)
+	h.handler.PlaylistLoaded(playlist)
+}
+
+func (h timedLoadHandler) SearchResultLoaded(tracks []lavalink.AudioTrack) {
+	observeLoad(h.origin, h.start)
+	h.handler.SearchResultLoaded(tracks)
+}
+
+func (h timedLoadHandler) NoMatches() {
+	observeLoad(h.origin, h.start)
+	h.handler.NoMatches()
+}
+
+func (h timedLoadHandler) LoadFailed(e lavalink.FriendlyException) {
+	observeLoad(h.origin, h.start)
+	h.handler.LoadFailed(e)
+}
+
+func loadItemTimed(ctx context.Context, client LavalinkClient, origin string, query string, handler lavalink.AudioLoadResultHandler) error {
+	start := time.Now()
+	err := client.LoadItemHandler(ctx, query, timedLoadHandler{handler: handler, origin: origin, start: start})
+	if err != nil {
+		observeLoad(origin, start)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package gobot
+
+import (
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Collects the metrics of the collector by name and label values.
+func collect(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	t.Helper()
+	ch := make(chan prometheus.Metric, 16)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+
+	values := map[string]float64{}
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		name := metric.Desc().String()
+		for _, label := range m.GetLabel() {
+			name = label.GetValue()
+		}
+		values[name] = m.GetGauge().GetValue()
+	}
+	return values
+}
+
+// Collects the gauges of the collector with the description by label value.
+func collectDesc(t *testing.T, collector prometheus.Collector, desc *prometheus.Desc) map[string]float64 {
+	t.Helper()
+	ch := make(chan prometheus.Metric, 64)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+
+	values := map[string]float64{}
+	for metric := range ch {
+		if metric.Desc() != desc {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		var label string
+		for _, pair := range m.GetLabel() {
+			label = pair.GetValue()
+		}
+		values[label] = m.GetGauge().GetValue()
+	}
+	return values
+}
+
+func TestPlayerCollector(t *testing.T) {
+	bot, _, _ := newTestBot(t)
+	collector := playerCollector{bot: bot}
+
+	if values := collect(t, collector); len(values) != 1 {
+		t.Errorf("expected only the active players without players, got %v", values)
+	}
+
+	playTracks(t, bot, testTrack("a", "A", lavalink.Minute), testTrack("b", "B", lavalink.Minute), testTrack("c", "C", lavalink.Minute))
+	values := collect(t, collector)
+	if values[activePlayersDesc.String()] != 1 {
+		t.Errorf("expected 1 active player, got %v", values)
+	}
+	// The playing song is not part of the queue
+	if values[testGuildID] != 2 {
+		t.Errorf("expected a queue of 2 songs in the test guild, got %v", values)
+	}
+
+	if err := bot.leave(bot.Session, testGuildID); err != nil {
+		t.Fatal(err)
+	}
+	if values := collect(t, collector); values[activePlayersDesc.String()] != 0 || len(values) != 1 {
+		t.Errorf("expected no players after leaving, got %v", values)
+	}
+}
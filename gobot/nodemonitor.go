@@ -0,0 +1,80 @@
+package gobot
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Tracks the connection state and stats of the lavalink nodes as disgolink plugin.
+// Disgolink writes the status and stats of nodes from their connection goroutines without
+// synchronizing reads, so health checks and metrics read them from here instead.
+// States belong to node instances instead of names, since a reload replaces a node with one of the same name
+// and closes the old node after the new one connected.
+type NodeMonitor struct {
+	nodes   map[lavalink.Node]nodeState // maps nodes to their last known state
+	nodesMu sync.RWMutex
+}
+
+type nodeState struct {
+	connected bool
+	stats     *lavalink.Stats
+}
+
+func NewNodeMonitor() *NodeMonitor {
+	return &NodeMonitor{nodes: map[lavalink.Node]nodeState{}}
+}
+
+// Status and last stats of the node, nil until lavalink sent them.
+func (m *NodeMonitor) State(node lavalink.Node) (lavalink.NodeStatus, *lavalink.Stats) {
+	m.nodesMu.RLock()
+	defer m.nodesMu.RUnlock()
+	state := m.nodes[node]
+	if !state.connected {
+		return lavalink.Disconnected, state.stats
+	}
+	return lavalink.Connected, state.stats
+}
+
+func (m *NodeMonitor) OnNodeOpen(node lavalink.Node) {
+	m.nodesMu.Lock()
+	defer m.nodesMu.Unlock()
+	state := m.nodes[node]
+	state.connected = true
+	m.nodes[node] = state
+}
+
+// Destroyed nodes are closed for good, so their state is forgotten.
+func (m *NodeMonitor) OnNodeDestroy(node lavalink.Node) {
+	m.nodesMu.Lock()
+	defer m.nodesMu.Unlock()
+	delete(m.nodes, node)
+}
+
+// Keeps the stats lavalink sends every minute.
+func (m *NodeMonitor) OnNodeMessageIn(node lavalink.Node, data []byte) {
+	var op struct {
+		Op lavalink.OpType `json:"op"`
+	}
+	if err := json.Unmarshal(data, &op); err != nil || op.Op != lavalink.OpTypeStats {
+		return
+	}
+	stats := &lavalink.Stats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		Logger.Warn("Could not decode stats of lavalink node ", node.Name(), ": ", err)
+		return
+	}
+
+	m.nodesMu.Lock()
+	defer m.nodesMu.Unlock()
+	state := m.nodes[node]
+	state.stats = stats
+	m.nodes[node] = state
+}
+
+func (m *NodeMonitor) OnNodeMessageOut(_ lavalink.Node, _ []byte) {}
+
+func (m *NodeMonitor) OnNewPlayer(_ lavalink.Player) {}
+
+func (m *NodeMonitor) OnDestroyPlayer(_ lavalink.Player) {}
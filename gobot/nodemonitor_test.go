@@ -0,0 +1,82 @@
+package gobot
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Node only implementing Name, which is all the monitor uses.
+type namedNode struct {
+	lavalink.Node
+	name string
+}
+
+func (n namedNode) Name() string {
+	return n.name
+}
+
+func TestNodeMonitor(t *testing.T) {
+	monitor := NewNodeMonitor()
+	node := namedNode{name: "main"}
+
+	if status, stats := monitor.State(node); status != lavalink.Disconnected || stats != nil {
+		t.Errorf("expected unknown node to be disconnected without stats, got %s %+v", status, stats)
+	}
+
+	monitor.OnNodeOpen(node)
+	monitor.OnNodeMessageIn(node, []byte(`{"op":"playerUpdate","guildId":"1","state":{"time":1,"position":1}}`))
+	monitor.OnNodeMessageIn(node, []byte(`{"op":"stats","players":3,"playingPlayers":2,"uptime":1000,"memory":{"used":64},"cpu":{"cores":2,"systemLoad":0.5,"lavalinkLoad":0.25}}`))
+	status, stats := monitor.State(node)
+	if status != lavalink.Connected {
+		t.Errorf("expected connected node, got %s", status)
+	}
+	if stats == nil || stats.Players != 3 || stats.PlayingPlayers != 2 || stats.Memory.Used != 64 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	monitor.OnNodeDestroy(node)
+	if status, stats := monitor.State(node); status != lavalink.Disconnected || stats != nil {
+		t.Errorf("expected destroyed node to be forgotten, got %s %+v", status, stats)
+	}
+}
+
+// Reloads connect a node with the same name before closing the old one.
+func TestNodeMonitorReplacedNode(t *testing.T) {
+	monitor := NewNodeMonitor()
+	old := &namedNode{name: "main"}
+	replacement := &namedNode{name: "main"}
+
+	monitor.OnNodeOpen(old)
+	monitor.OnNodeOpen(replacement)
+	monitor.OnNodeDestroy(old)
+
+	if status, _ := monitor.State(replacement); status != lavalink.Connected {
+		t.Errorf("expected the replacement to stay connected, got %s", status)
+	}
+	if status, _ := monitor.State(old); status != lavalink.Disconnected {
+		t.Errorf("expected the old node to be disconnected, got %s", status)
+	}
+}
+
+// Health checks read the monitor while the node connection updates it. Run with -race.
+func TestNodeMonitorConcurrentAccess(t *testing.T) {
+	monitor := NewNodeMonitor()
+	node := namedNode{name: "main"}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 100; n++ {
+			monitor.OnNodeOpen(node)
+			monitor.OnNodeMessageIn(node, []byte(`{"op":"stats","players":1}`))
+			monitor.OnNodeDestroy(node)
+		}
+	}()
+	for n := 0; n < 100; n++ {
+		monitor.State(node)
+	}
+	wg.Wait()
+}
@@ -1,19 +1,41 @@
 package gobot
 
 import (
+	"errors"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/disgolink/lavalink"
 )
 
 type PlayerManager struct {
 	lavalink.PlayerEventAdapter
-	Player        lavalink.Player
+	Player        AudioPlayer
+	Lavalink      LavalinkClient // loads related tracks for autoplay
 	Queue         []lavalink.AudioTrack
 	QueueMu       sync.Mutex
 	RepeatingMode RepeatingMode
-	PlayerSession *discordgo.Session
+	Section       *SectionLoop
+	SectionMu     sync.Mutex
+	History       []string // identifiers of recently played tracks
+	HistoryMu     sync.Mutex
+	GuildID       string
+	Settings      *GuildSettingsStore
+	IdleTimer     *time.Timer
+	IdleMu        sync.Mutex
+	OnIdle        func()    // called once nothing was played for the idle timeout of the guild
+	Bus           *EventBus // receives the events of the player
+}
+
+// A-B loop of a section in the playing track.
+// The loop is polled since lavalink only sends player updates every few seconds.
+type SectionLoop struct {
+	Start      lavalink.Duration
+	End        lavalink.Duration
+	identifier string
+	seekedAt   time.Time
+	stop       chan struct{}
 }
 
 type RepeatingMode int
@@ -22,12 +44,16 @@ const (
 	RepeatingModeOff = iota
 	RepeatingModeSong
 	RepeatingModeQueue
+	RepeatingModeSection
 )
 
+const sectionLoopInterval = 200 * time.Millisecond
+
 func (m *PlayerManager) AddQueue(tracks ...lavalink.AudioTrack) {
 	m.QueueMu.Lock()
 	defer m.QueueMu.Unlock()
 	m.Queue = append(m.Queue, tracks...)
+	m.emitQueueChanged()
 }
 
 func (m *PlayerManager) PopQueue() lavalink.AudioTrack {
@@ -38,6 +64,7 @@ func (m *PlayerManager) PopQueue() lavalink.AudioTrack {
 	}
 	var track lavalink.AudioTrack
 	track, m.Queue = m.Queue[0], m.Queue[1:]
+	m.emitQueueChanged()
 	return track
 }
 
@@ -54,6 +81,34 @@ func (m *PlayerManager) DeleteQueue() {
 	m.QueueMu.Lock()
 	defer m.QueueMu.Unlock()
 	m.Queue = []lavalink.AudioTrack{}
+	m.emitQueueChanged()
+}
+
+// Moves the queued track at index from to index to.
+func (m *PlayerManager) MoveQueue(from int, to int) error {
+	m.QueueMu.Lock()
+	defer m.QueueMu.Unlock()
+	if from < 0 || from >= len(m.Queue) || to < 0 || to >= len(m.Queue) {
+		return errors.New("queue position out of range")
+	}
+	track := m.Queue[from]
+	queue := append(m.Queue[:from:from], m.Queue[from+1:]...)
+	m.Queue = append(queue[:to:to], append([]lavalink.AudioTrack{track}, queue[to:]...)...)
+	m.emitQueueChanged()
+	return nil
+}
+
+// Removes the queued track at index.
+func (m *PlayerManager) RemoveQueue(index int) (lavalink.AudioTrack, error) {
+	m.QueueMu.Lock()
+	defer m.QueueMu.Unlock()
+	if index < 0 || index >= len(m.Queue) {
+		return nil, errors.New("queue position out of range")
+	}
+	track := m.Queue[index]
+	m.Queue = append(m.Queue[:index:index], m.Queue[index+1:]...)
+	m.emitQueueChanged()
+	return track, nil
 }
 
 func (m *PlayerManager) getAllTracks() []lavalink.AudioTrack {
@@ -86,61 +141,268 @@ func (m *PlayerManager) isPlaying() bool {
 }
 
 func (m *PlayerManager) setMode(mode RepeatingMode) {
+	m.SectionMu.Lock()
+	if mode != RepeatingModeSection {
+		m.cancelSectionLoop()
+	}
 	m.RepeatingMode = mode
+	m.SectionMu.Unlock()
+	m.emit(PlayerEvent{Type: EventModeChanged, Mode: repeatingModeNames[mode]})
+}
+
+// The repeating mode is guarded by SectionMu, since section loops turn it off when they stop.
+func (m *PlayerManager) mode() RepeatingMode {
+	m.SectionMu.Lock()
+	defer m.SectionMu.Unlock()
+	return m.RepeatingMode
+}
+
+// Starts polling the player position and seeks back to start whenever the end of the section is passed.
+// A running section loop is replaced.
+func (m *PlayerManager) startSectionLoop(track lavalink.AudioTrack, start lavalink.Duration, end lavalink.Duration) {
+	loop := &SectionLoop{
+		Start:      start,
+		End:        end,
+		identifier: track.Info().Identifier,
+		stop:       make(chan struct{}),
+	}
+
+	m.SectionMu.Lock()
+	m.cancelSectionLoop()
+	m.Section = loop
+	m.RepeatingMode = RepeatingModeSection
+	m.SectionMu.Unlock()
+	m.emit(PlayerEvent{Type: EventModeChanged, Mode: repeatingModeNames[RepeatingModeSection]})
+
+	go m.runSectionLoop(loop)
+}
+
+// Stops the section loop and announces that the section mode was turned off.
+func (m *PlayerManager) stopSectionLoop() {
+	m.SectionMu.Lock()
+	turnedOff := m.cancelSectionLoop()
+	m.SectionMu.Unlock()
+	if turnedOff {
+		m.emit(PlayerEvent{Type: EventModeChanged, Mode: repeatingModeNames[RepeatingModeOff]})
+	}
+}
+
+// Stops the section loop and turns the section mode off. Whether the mode changed is returned,
+// so the caller can emit the event once SectionMu, which has to be held, is released.
+func (m *PlayerManager) cancelSectionLoop() bool {
+	if m.Section == nil {
+		return false
+	}
+	close(m.Section.stop)
+	m.Section = nil
+	if m.RepeatingMode != RepeatingModeSection {
+		return false
+	}
+	m.RepeatingMode = RepeatingModeOff
+	return true
+}
+
+func (m *PlayerManager) runSectionLoop(loop *SectionLoop) {
+	ticker := time.NewTicker(sectionLoopInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-loop.stop:
+			return
+		case <-ticker.C:
+			m.SectionMu.Lock()
+			track := m.Player.PlayingTrack()
+			if track == nil || track.Info().Identifier != loop.identifier {
+				Logger.Debug("Track changed. Cancelling section loop.")
+				turnedOff := m.Section == loop && m.cancelSectionLoop()
+				m.SectionMu.Unlock()
+				if turnedOff {
+					m.emit(PlayerEvent{Type: EventModeChanged, Mode: repeatingModeNames[RepeatingModeOff]})
+				}
+				return
+			}
+
+			if m.Player.Paused() {
+				// Do not let the estimated position run away while paused
+				if !loop.seekedAt.IsZero() {
+					loop.seekedAt = loop.seekedAt.Add(sectionLoopInterval)
+				}
+				m.SectionMu.Unlock()
+				continue
+			}
+
+			if loop.position(m.Player) >= loop.End {
+				Logger.Debug("Section end passed. Seeking back to ", loop.Start)
+				if err := m.Player.Seek(loop.Start); err != nil {
+					Logger.Warn("Error seeking to section start: ", err)
+				}
+				loop.seekedAt = time.Now()
+			}
+			m.SectionMu.Unlock()
+		}
+	}
+}
+
+// The player position is only updated by lavalink player updates, so it is estimated after seeking until the next update.
+func (l *SectionLoop) position(player AudioPlayer) lavalink.Duration {
+	if !l.seekedAt.IsZero() {
+		return l.Start + lavalink.Duration(time.Since(l.seekedAt).Milliseconds())
+	}
+	return player.Position()
+}
+
+func (m *PlayerManager) OnPlayerUpdate(player lavalink.Player, state lavalink.PlayerState) {
+	m.SectionMu.Lock()
+	if m.Section != nil && state.Time.After(m.Section.seekedAt) {
+		m.Section.seekedAt = time.Time{}
+	}
+	m.SectionMu.Unlock()
+
+	positionMs := state.Position.Milliseconds()
+	m.emit(PlayerEvent{Type: EventPlayerUpdate, PositionMs: &positionMs})
+}
+
+func (m *PlayerManager) OnPlayerPause(player lavalink.Player) {
+	m.emit(PlayerEvent{Type: EventPaused, Track: playerTrackState(player)})
+}
+
+func (m *PlayerManager) OnPlayerResume(player lavalink.Player) {
+	m.emit(PlayerEvent{Type: EventResumed, Track: playerTrackState(player)})
+}
+
+// Publishes a player event on the bus. Does not block, so it may be called while the queue is locked.
+func (m *PlayerManager) emit(event PlayerEvent) {
+	event.GuildID = m.GuildID
+	event.Time = time.Now().UTC()
+	recordMetrics(event)
+	if m.Bus != nil {
+		m.Bus.Publish(event)
+	}
+}
+
+// Expects the queue to be locked.
+func (m *PlayerManager) emitQueueChanged() {
+	length := len(m.Queue)
+	m.emit(PlayerEvent{Type: EventQueueChanged, QueueLength: &length})
+}
+
+func playerTrackState(player AudioPlayer) *TrackState {
+	if track := player.PlayingTrack(); track != nil {
+		state := newTrackState(track)
+		return &state
+	}
+	return nil
+}
+
+// Starts the idle timer of the guild. Nothing happens if the guild has no idle timeout.
+func (m *PlayerManager) startIdleTimer() {
+	timeout := m.Settings.Get(m.GuildID).IdleTimeout
+	if timeout == 0 || m.OnIdle == nil {
+		return
+	}
+
+	m.IdleMu.Lock()
+	defer m.IdleMu.Unlock()
+	if m.IdleTimer != nil {
+		m.IdleTimer.Stop()
+	}
+	m.IdleTimer = time.AfterFunc(time.Duration(timeout)*time.Minute, func() {
+		if !m.isPlaying() {
+			Logger.Debug("Player was idle for ", timeout, " minutes.")
+			m.OnIdle()
+		}
+	})
+}
+
+func (m *PlayerManager) stopIdleTimer() {
+	m.IdleMu.Lock()
+	defer m.IdleMu.Unlock()
+	if m.IdleTimer != nil {
+		m.IdleTimer.Stop()
+		m.IdleTimer = nil
+	}
 }
 
 func (m *PlayerManager) OnWebSocketClosed(player lavalink.Player, code int, reason string, byRemote bool) {
 	Logger.Debug("Websocket to lavalink closed with code ", code, " and reason ", reason, " from remote ", byRemote)
 	// m.Player = m.Player.Node().Lavalink().Player(player.GuildID())
-	m.Player.Node().Lavalink().RestorePlayer(player.Export())
+	player.Node().Lavalink().RestorePlayer(player.Export())
 }
 
 func (m *PlayerManager) OnTrackStart(player lavalink.Player, track lavalink.AudioTrack) {
 	Logger.Debug("Track started: ", track.Info().Title)
-	if err := m.PlayerSession.UpdateGameStatus(0, track.Info().Title); err != nil {
-		Logger.Warn("Error updating status: ", err)
-	}
+	m.addHistory(track)
+	m.stopIdleTimer()
+	trackState := newTrackState(track)
+	m.emit(PlayerEvent{Type: EventTrackStart, Track: &trackState})
 }
 
 func (m *PlayerManager) OnTrackException(player lavalink.Player, track lavalink.AudioTrack, exception lavalink.FriendlyException) {
 	Logger.Debug("Track exception: ", track)
+	trackState := newTrackState(track)
+	m.emit(PlayerEvent{Type: EventTrackException, Track: &trackState, Error: exception.Message})
 }
 
 func (m *PlayerManager) OnTrackStuck(player lavalink.Player, track lavalink.AudioTrack, thresholdMs lavalink.Duration) {
 	Logger.Debug("Track stuck: ", track)
+	trackState := newTrackState(track)
+	m.emit(PlayerEvent{Type: EventTrackStuck, Track: &trackState, ThresholdMs: thresholdMs.Milliseconds()})
 }
 
 func (m *PlayerManager) OnTrackEnd(player lavalink.Player, track lavalink.AudioTrack, endReason lavalink.AudioTrackEndReason) {
 	Logger.Debug("Track ended: ", track.Info().Title, " with end reason ", endReason)
+	// Stopped again by the next track start
+	m.startIdleTimer()
+	trackState := newTrackState(track)
+	m.emit(PlayerEvent{Type: EventTrackEnd, Track: &trackState, Reason: strings.ToLower(string(endReason))})
 
 	if !endReason.MayStartNext() {
 		return
 	}
 
-	switch m.RepeatingMode {
+	switch m.mode() {
 	case RepeatingModeOff:
 		if nextTrack := m.PopQueue(); nextTrack != nil {
 			Logger.Debug("Next track after trackEnd event: ", nextTrack)
-			if err := player.Play(nextTrack); err != nil {
+			if err := m.Player.Play(nextTrack); err != nil {
 				Logger.Warn("Error playing next track: ", err)
 			}
+		} else if m.autoplayEnabled() {
+			m.autoplay(track)
 		}
 	case RepeatingModeSong:
-		if err := player.Play(track.Clone()); err != nil {
+		if err := m.Player.Play(track.Clone()); err != nil {
 			Logger.Warn("Error playing next track: ", err)
 		}
 
+	case RepeatingModeSection:
+		// The section end may be the end of the track, so finished tracks are replayed from the section start
+		m.SectionMu.Lock()
+		if section := m.Section; section != nil && endReason == lavalink.AudioTrackEndReasonFinished {
+			nextTrack := track.Clone()
+			nextTrack.SetPosition(section.Start)
+			if err := m.Player.Play(nextTrack); err != nil {
+				Logger.Warn("Error replaying section: ", err)
+			}
+			section.seekedAt = time.Now()
+			m.SectionMu.Unlock()
+			return
+		}
+		m.SectionMu.Unlock()
+		m.stopSectionLoop()
+		if nextTrack := m.PopQueue(); nextTrack != nil {
+			if err := m.Player.Play(nextTrack); err != nil {
+				Logger.Warn("Error playing next track: ", err)
+			}
+		}
+
 	case RepeatingModeQueue:
 		m.AddQueue(track)
 		if nextTrack := m.PopQueue(); nextTrack != nil {
-			if err := player.Play(nextTrack); err != nil {
+			if err := m.Player.Play(nextTrack); err != nil {
 				Logger.Warn("Error playing next track: ", err)
 			}
 		}
 	}
-
-	if err := m.PlayerSession.UpdateGameStatus(0, ""); err != nil {
-		Logger.Warn("Error updating status: ", err)
-	}
-
 }
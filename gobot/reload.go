@@ -0,0 +1,206 @@
+package gobot
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Editors write files in several steps, so changes are collected before reloading
+const reloadDebounce = 500 * time.Millisecond
+
+// Reloads the configuration when the file changes or on SIGHUP.
+// Settings with the reload tag are applied at runtime, other changes are reported as requiring a restart.
+func (b *Bot) watchConfig(configFile string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	// Watch the directory since editors often replace the file instead of writing it
+	var events chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		Logger.Warn("Could not watch configuration file. Reload with SIGHUP instead: ", err)
+	} else if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		Logger.Warn("Could not watch configuration file. Reload with SIGHUP instead: ", err)
+		watcher.Close()
+	} else {
+		defer watcher.Close()
+		events = watcher.Events
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-hup:
+			Logger.Info("Received SIGHUP. Reloading configuration.")
+			b.reloadConfig(configFile)
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) == filepath.Clean(configFile) && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			Logger.Info("Configuration file changed. Reloading configuration.")
+			b.reloadConfig(configFile)
+		}
+	}
+}
+
+// Limits and permissions are guild settings, so the guild settings file is reloaded along with the configuration.
+func (b *Bot) reloadConfig(configFile string) {
+	if err := b.Settings.Reload(); err != nil {
+		Logger.Warn("Keeping current guild settings since the file is invalid: ", err)
+	}
+
+	conf, err := LoadConfig(configFile)
+	if err != nil {
+		Logger.Warn("Keeping current configuration since the new one is invalid: ", err)
+		return
+	}
+
+	old := b.config()
+	applied, reloaded, restart := splitConfigChanges(old, conf)
+	if len(reloaded) == 0 && len(restart) == 0 {
+		Logger.Info("Configuration did not change.")
+		return
+	}
+	for _, field := range restart {
+		Logger.Warn("Configuration " + field + " changed but requires a restart to take effect.")
+	}
+
+	if configChanged(old, applied, "LogFile", "LogLevel", "LogFormat", "LogTimeStamp") {
+		SetLoggerConfig(applied)
+	}
+
+	if configChanged(old, applied, "LavalinkHost", "LavalinkPort", "LavalinkPW", "LavalinkNode", "Secure") {
+		if err := b.replaceNode(old, applied); err != nil {
+			Logger.Warn("Could not switch to new lavalink node. Keeping current node: ", err)
+			applied.LavalinkHost, applied.LavalinkPort, applied.LavalinkPW = old.LavalinkHost, old.LavalinkPort, old.LavalinkPW
+			applied.LavalinkNode, applied.Secure = old.LavalinkNode, old.Secure
+		}
+	} else if configChanged(old, applied, "ResumeKey", "ResumeTimeOut") {
+		if err := b.Link.BestNode().ConfigureResuming(applied.ResumeKey, applied.ResumeTimeOut); err != nil {
+			Logger.Warn("Could not configure resuming: ", err)
+		}
+	}
+
+	if configChanged(old, applied, "LibraryDir") {
+		b.setLibrary(applied.LibraryDir)
+	}
+
+	if configChanged(old, applied, "ThemeDir") {
+		setThemes(applied.ThemeDir)
+	}
+
+	b.ConfigMu.Lock()
+	b.Config = applied
+	b.ConfigMu.Unlock()
+
+	// Reverted changes were reported as failures above
+	for _, field := range changedConfigFields(old, applied) {
+		Logger.Info("Applied changed configuration " + field + ".")
+	}
+}
+
+// Applies the changed fields with the reload tag to the old configuration.
+// Returns the result and the names of the applied fields and of the changed fields that require a restart.
+func splitConfigChanges(old Configuration, conf Configuration) (Configuration, []string, []string) {
+	var reloaded, restart []string
+	applied := old
+	appliedValues := reflect.ValueOf(&applied).Elem()
+	newValues := reflect.ValueOf(conf)
+	for _, field := range changedConfigFields(old, conf) {
+		structField, _ := reflect.TypeOf(conf).FieldByName(field)
+		if structField.Tag.Get("reload") != "true" {
+			restart = append(restart, field)
+			continue
+		}
+		appliedValues.FieldByName(field).Set(newValues.FieldByName(field))
+		reloaded = append(reloaded, field)
+	}
+	return applied, reloaded, restart
+}
+
+// Returns the names of all fields that differ.
+func changedConfigFields(old Configuration, conf Configuration) []string {
+	var changed []string
+	oldValues, newValues := reflect.ValueOf(old), reflect.ValueOf(conf)
+	for i := 0; i < oldValues.NumField(); i++ {
+		if !reflect.DeepEqual(oldValues.Field(i).Interface(), newValues.Field(i).Interface()) {
+			changed = append(changed, oldValues.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+func configChanged(old Configuration, conf Configuration, fields ...string) bool {
+	oldValues, newValues := reflect.ValueOf(old), reflect.ValueOf(conf)
+	for _, field := range fields {
+		if !reflect.DeepEqual(oldValues.FieldByName(field).Interface(), newValues.FieldByName(field).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Connects to the new lavalink node, moves all players over and closes the old node.
+func (b *Bot) replaceNode(old Configuration, conf Configuration) error {
+	oldNode := b.Link.Node(old.LavalinkNode)
+
+	node, err := b.addNode(conf)
+	if err != nil {
+		return err
+	}
+	if err := node.ConfigureResuming(conf.ResumeKey, conf.ResumeTimeOut); err != nil {
+		Logger.Warn("Could not configure resuming: ", err)
+	}
+
+	for _, manager := range b.managers() {
+		manager.Player.ChangeNode(node)
+	}
+
+	if oldNode != nil && old.LavalinkNode != conf.LavalinkNode {
+		b.Link.RemoveNode(old.LavalinkNode)
+	} else if oldNode != nil {
+		// The new node replaced the old one with the same name
+		oldNode.Close()
+	}
+
+	Logger.Info("Switched to lavalink node ", conf.LavalinkNode, ".")
+	return nil
+}
+
+func (b *Bot) setLibrary(dir string) {
+	var library *Library
+	if dir != "none" {
+		library = NewLibrary(dir)
+	}
+
+	b.ConfigMu.Lock()
+	b.Library = library
+	b.ConfigMu.Unlock()
+
+	if library != nil {
+		go func() {
+			if _, err := library.Scan(context.TODO(), b.Lavalink); err != nil {
+				Logger.Warn("Failed to scan music library: ", err)
+			}
+		}()
+	}
+}
+
+// Loads the custom response themes. Guilds using a removed theme fall back to the default theme.
+func setThemes(dir string) {
+	if err := Messages.LoadCustomThemes(dir); err != nil {
+		Logger.Warn("Failed to load custom themes. Keeping the current ones: ", err)
+	}
+}
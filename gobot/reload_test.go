@@ -0,0 +1,100 @@
+package gobot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitConfigChanges(t *testing.T) {
+	old, err := defaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.DiscordToken = "token"
+	old.DevGuildIDs = []string{"1"}
+
+	tests := []struct {
+		name     string
+		change   func(conf *Configuration)
+		reloaded []string
+		restart  []string
+	}{
+		{name: "unchanged", change: func(conf *Configuration) {}},
+		{
+			name:     "reloadable",
+			change:   func(conf *Configuration) { conf.LogLevel = "debug"; conf.AdminToken = "secret" },
+			reloaded: []string{"LogLevel", "AdminToken"},
+		},
+		{
+			name:    "restart required",
+			change:  func(conf *Configuration) { conf.DiscordToken = "other"; conf.DevGuildIDs = []string{"1", "2"} },
+			restart: []string{"DiscordToken", "DevGuildIDs"},
+		},
+		{
+			name:     "mixed",
+			change:   func(conf *Configuration) { conf.HTTPAddress = ":9090"; conf.LibraryDir = "music" },
+			reloaded: []string{"LibraryDir"},
+			restart:  []string{"HTTPAddress"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := old
+			conf.DevGuildIDs = append([]string{}, old.DevGuildIDs...)
+			test.change(&conf)
+
+			applied, reloaded, restart := splitConfigChanges(old, conf)
+			if !reflect.DeepEqual(reloaded, test.reloaded) {
+				t.Errorf("expected reloaded %v, got %v", test.reloaded, reloaded)
+			}
+			if !reflect.DeepEqual(restart, test.restart) {
+				t.Errorf("expected restart %v, got %v", test.restart, restart)
+			}
+
+			// Only reloadable fields are applied, the others keep their current value
+			expected := old
+			appliedValues, newValues := reflect.ValueOf(&expected).Elem(), reflect.ValueOf(conf)
+			for _, field := range test.reloaded {
+				appliedValues.FieldByName(field).Set(newValues.FieldByName(field))
+			}
+			if !reflect.DeepEqual(applied, expected) {
+				t.Errorf("expected applied configuration %+v, got %+v", expected, applied)
+			}
+		})
+	}
+}
+
+func TestGuildSettingsReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "guilds.json")
+	store, err := NewGuildSettingsStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("1", "dj_role", "500"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Limits and permissions edited in the file apply on reload
+	if err := os.WriteFile(file, []byte(`{"1": {"max_queue_length": 3}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if settings := store.Get("1"); settings.MaxQueueLength != 3 || settings.DJRole != "" {
+		t.Errorf("expected the edited settings, got %+v", settings)
+	}
+
+	if err := os.WriteFile(file, []byte(`{invalid`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reload(); err == nil {
+		t.Error("expected an invalid file to fail")
+	}
+	if settings := store.Get("1"); settings.MaxQueueLength != 3 {
+		t.Errorf("expected the current settings to be kept, got %+v", settings)
+	}
+}
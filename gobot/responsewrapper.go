@@ -2,99 +2,124 @@ package gobot
 
 import "github.com/bwmarrin/discordgo"
 
-// For readability to shorten the code where the same responses are created
+// Message sent in answer to an interaction. Responses are only visible to the user of the interaction
+// unless they are made public. Build them with NewResponse and send them with Interaction or FollowUp.
+type Response struct {
+	Content    string
+	Embeds     []*discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
+	Public     bool
+}
 
-func SingleInteractionResponse(content string, interactionResponseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
-	return &discordgo.InteractionResponse{
-		Type: interactionResponseType,
-		Data: &discordgo.InteractionResponseData{
-			Content: content,
-			Flags:   1 << 6,
-		},
-	}
+func NewResponse(content string) *Response {
+	return &Response{Content: content}
 }
 
-func SingleFollowUpResponse(content string) *discordgo.WebhookParams {
-	return &discordgo.WebhookParams{
-		Content: content,
-		Flags:   1 << 6,
-	}
+// Shows the response to everyone in the channel instead of only the user.
+func (r *Response) Visible(public bool) *Response {
+	r.Public = public
+	return r
 }
 
-func SingleSelectMenuFollowUpResponse(content string, customID string, placeHolder string, options []discordgo.SelectMenuOption) *discordgo.WebhookParams {
-	return &discordgo.WebhookParams{
-		Content: content,
-		Flags:   1 << 6,
-		Components: []discordgo.MessageComponent{
-			discordgo.ActionsRow{
-				Components: []discordgo.MessageComponent{
-					discordgo.SelectMenu{
-						CustomID:    customID,
-						Placeholder: placeHolder,
-						Options:     options,
-					},
-				},
-			},
+func (r *Response) Embed(embed *discordgo.MessageEmbed) *Response {
+	r.Embeds = append(r.Embeds, embed)
+	return r
+}
+
+// Adds a link button. Buttons share a row until it is full.
+func (r *Response) Button(label string, url string, emojiName string) *Response {
+	button := discordgo.Button{
+		Label:    label,
+		Style:    discordgo.LinkButton,
+		Disabled: false,
+		URL:      url,
+		Emoji: discordgo.ComponentEmoji{
+			Name: emojiName,
 		},
 	}
+	if n := len(r.Components); n > 0 {
+		if row, ok := r.Components[n-1].(discordgo.ActionsRow); ok && len(row.Components) < 5 {
+			if _, isButton := row.Components[0].(discordgo.Button); isButton {
+				row.Components = append(row.Components, button)
+				r.Components[n-1] = row
+				return r
+			}
+		}
+	}
+	r.Components = append(r.Components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{button}})
+	return r
 }
 
-func SingleEmbedFollowUpResponse(content string, title string, messageEmbedField []*discordgo.MessageEmbedField) *discordgo.WebhookParams {
-	return &discordgo.WebhookParams{
-		Content: content,
-		Flags:   1 << 6,
-		Embeds: []*discordgo.MessageEmbed{
-			{
-				Title:  title,
-				Fields: messageEmbedField,
+// Adds a select menu in its own row.
+func (r *Response) SelectMenu(customID string, placeHolder string, options []discordgo.SelectMenuOption) *Response {
+	r.Components = append(r.Components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    customID,
+				Placeholder: placeHolder,
+				Options:     options,
 			},
 		},
+	})
+	return r
+}
+
+func (r *Response) flags() uint64 {
+	if r.Public {
+		return 0
 	}
+	return uint64(discordgo.MessageFlagsEphemeral)
 }
 
-func SingleButtonInteractionResponse(content string, buttonLabel string, url string, emojiName string, interactionResponseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
+// Interaction response of the type. The visibility of deferred responses also applies to the first follow up.
+func (r *Response) Interaction(interactionResponseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
 	return &discordgo.InteractionResponse{
 		Type: interactionResponseType,
 		Data: &discordgo.InteractionResponseData{
-			Content: content,
-			Flags:   1 << 6,
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.Button{
-							Label:    buttonLabel,
-							Style:    discordgo.LinkButton,
-							Disabled: false,
-							URL:      url,
-							Emoji: discordgo.ComponentEmoji{
-								Name: emojiName,
-							},
-						},
-					},
-				},
-			},
+			Content:    r.Content,
+			Flags:      r.flags(),
+			Embeds:     r.Embeds,
+			Components: r.Components,
 		},
 	}
 }
 
-func SingleButtonFollowUpResponse(content string, buttonLabel string, url string, emojiName string) *discordgo.WebhookParams {
+func (r *Response) FollowUp() *discordgo.WebhookParams {
 	return &discordgo.WebhookParams{
-		Content: content,
-		Flags:   1 << 6,
-		Components: []discordgo.MessageComponent{
-			discordgo.ActionsRow{
-				Components: []discordgo.MessageComponent{
-					discordgo.Button{
-						Label:    buttonLabel,
-						Style:    discordgo.LinkButton,
-						Disabled: false,
-						URL:      url,
-						Emoji: discordgo.ComponentEmoji{
-							Name: emojiName,
-						},
-					},
-				},
-			},
-		},
+		Content:    r.Content,
+		Flags:      r.flags(),
+		Embeds:     r.Embeds,
+		Components: r.Components,
 	}
 }
+
+// For readability to shorten the code where the same private responses are created
+
+func SingleInteractionResponse(content string, interactionResponseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
+	return NewResponse(content).Interaction(interactionResponseType)
+}
+
+func SingleFollowUpResponse(content string) *discordgo.WebhookParams {
+	return NewResponse(content).FollowUp()
+}
+
+func SingleSelectMenuFollowUpResponse(content string, customID string, placeHolder string, options []discordgo.SelectMenuOption) *discordgo.WebhookParams {
+	return NewResponse(content).SelectMenu(customID, placeHolder, options).FollowUp()
+}
+
+func SingleEmbedFollowUpResponse(content string, title string, messageEmbedField []*discordgo.MessageEmbedField) *discordgo.WebhookParams {
+	return NewResponse(content).Embed(&discordgo.MessageEmbed{Title: title, Fields: messageEmbedField}).FollowUp()
+}
+
+func SingleEmbedInteractionResponse(content string, title string, messageEmbedField []*discordgo.MessageEmbedField) *discordgo.InteractionResponse {
+	return NewResponse(content).Embed(&discordgo.MessageEmbed{Title: title, Fields: messageEmbedField}).
+		Interaction(discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+func SingleButtonInteractionResponse(content string, buttonLabel string, url string, emojiName string, interactionResponseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
+	return NewResponse(content).Button(buttonLabel, url, emojiName).Interaction(interactionResponseType)
+}
+
+func SingleButtonFollowUpResponse(content string, buttonLabel string, url string, emojiName string) *discordgo.WebhookParams {
+	return NewResponse(content).Button(buttonLabel, url, emojiName).FollowUp()
+}
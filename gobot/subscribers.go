@@ -0,0 +1,80 @@
+package gobot
+
+import "github.com/bwmarrin/discordgo"
+
+// Registers the side effects of player and command events.
+func (b *Bot) subscribe() {
+	b.Bus.Subscribe("status", b.updateStatus)
+	b.Bus.Subscribe("announcements", b.announceTrack)
+	b.Bus.Subscribe("queue announcements", b.announceQueued)
+	b.Bus.Subscribe("dashboard", func(event interface{}) {
+		if playerEvent, ok := event.(PlayerEvent); ok {
+			b.Dashboard.notify(playerEvent.GuildID)
+		}
+	})
+	b.Bus.Subscribe("event stream", func(event interface{}) {
+		if playerEvent, ok := event.(PlayerEvent); ok {
+			b.Events.publish(playerEvent)
+		}
+	})
+}
+
+// Shows the playing track as game status.
+func (b *Bot) updateStatus(event interface{}) {
+	playerEvent, ok := event.(PlayerEvent)
+	if !ok {
+		return
+	}
+
+	var status string
+	switch playerEvent.Type {
+	case EventTrackStart:
+		status = playerEvent.Track.Title
+	case EventTrackEnd, EventPlayerDestroyed:
+	default:
+		return
+	}
+	if err := b.Session.UpdateGameStatus(0, status); err != nil {
+		Logger.Warn("Error updating status: ", err)
+	}
+}
+
+// Announces started tracks in the announce channel of the guild.
+func (b *Bot) announceTrack(event interface{}) {
+	playerEvent, ok := event.(PlayerEvent)
+	if !ok || playerEvent.Type != EventTrackStart {
+		return
+	}
+
+	if channelID := b.Settings.Get(playerEvent.GuildID).AnnounceChannel; channelID != "" {
+		style := b.guildStyle(playerEvent.GuildID)
+		message := &discordgo.MessageSend{
+			Content: Messages.Text(style, "announce.playing", Args{"Title": playerEvent.Track.Title}),
+			Embeds:  []*discordgo.MessageEmbed{NewTrackEmbed(style, *playerEvent.Track).Build()},
+		}
+		if _, err := b.Session.ChannelMessageSendComplex(channelID, message); err != nil {
+			Logger.Warn("Error announcing track: ", err)
+		}
+	}
+}
+
+// Announces songs added with commands in the announce channel if the guild enabled it.
+func (b *Bot) announceQueued(event interface{}) {
+	queuedEvent, ok := event.(QueuedEvent)
+	if !ok || len(queuedEvent.Titles) == 0 {
+		return
+	}
+
+	settings := b.Settings.Get(queuedEvent.GuildID)
+	if !settings.AnnounceQueued || settings.AnnounceChannel == "" {
+		return
+	}
+	style := b.guildStyle(queuedEvent.GuildID)
+	content := Messages.Text(style, "announce.queued", Args{"User": queuedEvent.User, "Title": queuedEvent.Titles[0]})
+	if len(queuedEvent.Titles) > 1 {
+		content = Messages.Text(style, "announce.queued_many", Args{"User": queuedEvent.User, "Count": len(queuedEvent.Titles)})
+	}
+	if _, err := b.Session.ChannelMessageSend(settings.AnnounceChannel, content); err != nil {
+		Logger.Warn("Error announcing queued songs: ", err)
+	}
+}
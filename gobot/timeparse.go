@@ -0,0 +1,141 @@
+package gobot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+// Whole hours, minutes and seconds like 90s or 1h2m3s. Fractions and smaller units are rejected.
+var durationPattern = regexp.MustCompile(`^(\d+h)?(\d+m)?(\d+s)?$`)
+
+// Parses human time formats like 90, 1:23, 1:02:03, 90s or 1h2m3s into a lavalink duration.
+// Plain numbers are interpreted as seconds.
+func parseTimestamp(value string) (lavalink.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("empty timestamp")
+	}
+
+	if strings.HasPrefix(value, "-") {
+		return 0, errors.New("negative timestamp " + value)
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return lavalink.Duration(seconds) * lavalink.Second, nil
+	}
+
+	if strings.Contains(value, ":") {
+		return parseColonTimestamp(value)
+	}
+
+	if !durationPattern.MatchString(value) {
+		return 0, errors.New("unsupported timestamp " + value)
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported timestamp %s: %w", value, err)
+	}
+
+	return lavalink.Duration(duration.Milliseconds()), nil
+}
+
+// Parses signed timestamps like -30, +1:00 or -1m for relative positions.
+func parseRelativeTimestamp(value string) (lavalink.Duration, error) {
+	value = strings.TrimSpace(value)
+	sign := lavalink.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+		value = value[1:]
+	} else if strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	position, err := parseTimestamp(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return sign * position, nil
+}
+
+// Parses m:ss and h:mm:ss timestamps.
+func parseColonTimestamp(value string) (lavalink.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, errors.New("too many sections in timestamp " + value)
+	}
+
+	var total lavalink.Duration
+	for i, part := range parts {
+		// Signs are only allowed in front of relative timestamps, not in their sections
+		number, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return 0, errors.New("invalid section in timestamp " + value)
+		}
+		// Only the leading section may exceed the range of minutes and seconds
+		if i > 0 && number >= 60 {
+			return 0, errors.New("section out of range in timestamp " + value)
+		}
+		total = total*60 + lavalink.Duration(number)
+	}
+
+	return total * lavalink.Second, nil
+}
+
+// Formats a duration as m:ss or h:mm:ss for user facing messages.
+func formatTimestamp(d lavalink.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d.Hours() > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", d.Hours(), d.MinutesPart(), d.SecondsPart())
+	}
+	return fmt.Sprintf("%d:%02d", d.Minutes(), d.SecondsPart())
+}
+
+// Extracts the start offset of YouTube links (?t=90, &t=1m30s, #t=90).
+// Returns 0 if the query is not a YouTube link or does not contain a timestamp.
+func urlTimestamp(query string) lavalink.Duration {
+	u, err := url.Parse(query)
+	if err != nil {
+		return 0
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "youtu.be" && host != "youtube.com" && host != "m.youtube.com" && host != "music.youtube.com" {
+		return 0
+	}
+
+	t := u.Query().Get("t")
+	if t == "" {
+		if fragment, err := url.ParseQuery(u.Fragment); err == nil {
+			t = fragment.Get("t")
+		}
+	}
+	if t == "" {
+		return 0
+	}
+
+	start, err := parseTimestamp(t)
+	if err != nil {
+		Logger.Debug("Ignoring unsupported url timestamp: ", t)
+		return 0
+	}
+
+	return start
+}
+
+// Sets the start position of a track that has not been played yet.
+// Positions beyond the track length and streams are ignored.
+func setStartPosition(track lavalink.AudioTrack, start lavalink.Duration) {
+	if start <= 0 || track.Info().IsStream || start >= track.Info().Length {
+		return
+	}
+	track.SetPosition(start)
+}
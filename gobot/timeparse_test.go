@@ -0,0 +1,140 @@
+package gobot
+
+import (
+	"testing"
+
+	"github.com/disgoorg/disgolink/lavalink"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected lavalink.Duration
+		valid    bool
+	}{
+		{value: "90", expected: 90 * lavalink.Second, valid: true},
+		{value: "30", expected: 30 * lavalink.Second, valid: true},
+		{value: " 0 ", expected: 0, valid: true},
+		{value: "1:30", expected: 90 * lavalink.Second, valid: true},
+		{value: "1:02:03", expected: lavalink.Hour + 2*lavalink.Minute + 3*lavalink.Second, valid: true},
+		{value: "90:00", expected: 90 * lavalink.Minute, valid: true},
+		{value: "1h", expected: lavalink.Hour, valid: true},
+		{value: "90s", expected: 90 * lavalink.Second, valid: true},
+		{value: "1h2m3s", expected: lavalink.Hour + 2*lavalink.Minute + 3*lavalink.Second, valid: true},
+		{value: ""},
+		{value: "soon"},
+		{value: "-30"},
+		{value: "+30"},
+		{value: "-2m"},
+		{value: "1:60"},
+		{value: "-0:30"},
+		{value: "+0:30"},
+		{value: "1::30"},
+		{value: "1:2:3:4"},
+		{value: "500ms"},
+		{value: "1.5h"},
+		{value: "1.5"},
+		{value: "1m1h"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			position, err := parseTimestamp(test.value)
+			if test.valid && (err != nil || position != test.expected) {
+				t.Errorf("expected %s, got %s (%v)", formatTimestamp(test.expected), formatTimestamp(position), err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected %q to be rejected, got %s", test.value, formatTimestamp(position))
+			}
+		})
+	}
+}
+
+func TestParseColonTimestamp(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected lavalink.Duration
+		valid    bool
+	}{
+		{value: "0:30", expected: 30 * lavalink.Second, valid: true},
+		{value: "2:00:00", expected: 2 * lavalink.Hour, valid: true},
+		{value: "1:60"},
+		{value: "1:00:60"},
+		{value: "-1:30"},
+		{value: "1:-30"},
+		{value: "1:3.5"},
+		{value: ":30"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			position, err := parseColonTimestamp(test.value)
+			if test.valid && (err != nil || position != test.expected) {
+				t.Errorf("expected %s, got %s (%v)", formatTimestamp(test.expected), formatTimestamp(position), err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected %q to be rejected, got %s", test.value, formatTimestamp(position))
+			}
+		})
+	}
+}
+
+func TestParseRelativeTimestamp(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected lavalink.Duration
+		valid    bool
+	}{
+		{value: "30", expected: 30 * lavalink.Second, valid: true},
+		{value: "+30", expected: 30 * lavalink.Second, valid: true},
+		{value: "-30", expected: -30 * lavalink.Second, valid: true},
+		{value: "-2m", expected: -2 * lavalink.Minute, valid: true},
+		{value: "+1:00", expected: lavalink.Minute, valid: true},
+		{value: "-0:30", expected: -30 * lavalink.Second, valid: true},
+		{value: "--30"},
+		{value: "-+30"},
+		{value: "-500ms"},
+		{value: "+1.5m"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			position, err := parseRelativeTimestamp(test.value)
+			if test.valid && (err != nil || position != test.expected) {
+				t.Errorf("expected %d ms, got %d ms (%v)", test.expected, position, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected %q to be rejected, got %d ms", test.value, position)
+			}
+		})
+	}
+}
+
+func TestURLTimestamp(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected lavalink.Duration
+	}{
+		{query: "https://youtu.be/dQw4w9WgXcQ?t=90", expected: 90 * lavalink.Second},
+		{query: "https://youtu.be/dQw4w9WgXcQ?t=1m30s", expected: 90 * lavalink.Second},
+		{query: "https://youtu.be/dQw4w9WgXcQ#t=1m30s", expected: 90 * lavalink.Second},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1m30s", expected: 90 * lavalink.Second},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ#t=90", expected: 90 * lavalink.Second},
+		{query: "https://m.youtube.com/watch?v=dQw4w9WgXcQ&t=1h", expected: lavalink.Hour},
+		{query: "https://music.youtube.com/watch?v=dQw4w9WgXcQ&t=30", expected: 30 * lavalink.Second},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1.5m"},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=500ms"},
+		{query: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=-30"},
+		{query: "https://example.com/watch?v=dQw4w9WgXcQ&t=90"},
+		{query: "never gonna give you up t=90"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			if start := urlTimestamp(test.query); start != test.expected {
+				t.Errorf("expected %d ms, got %d ms", test.expected, start)
+			}
+		})
+	}
+}